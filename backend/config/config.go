@@ -4,6 +4,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,6 +22,9 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 
+	// Indicator seeding: "missing" (insert missing defaults) or "sync" (overwrite with defaults)
+	SeedMode string
+
 	// Google OAuth
 	GoogleClientID     string
 	GoogleClientSecret string
@@ -30,9 +35,55 @@ type Config struct {
 	SheetName       string
 	SpreadsheetYear int
 
+	// Optional service-account JSON key used for spreadsheet reads instead of user tokens
+	GoogleServiceAccountKeyFile string
+
+	// KPI data source: "sheets" (default), "upload" or "fake" for offline demos and CI
+	KPISource string
+
+	// Spreadsheet reads are cached for KPICacheTTL (0 disables) and served stale for a
+	// further KPICacheStaleWindow while they are refreshed in the background
+	KPICacheTTL         time.Duration
+	KPICacheStaleWindow time.Duration
+
+	// Overall score: "green_ratio", "weighted_green_ratio" or "weighted_attainment"
+	OverallScoreMethod string
+	AttainmentCeiling  float64 // Cap on each indicator's attainment (%) for weighted_attainment
+
+	// Automatic snapshots: cron expression (empty = disabled) and the account whose token is used
+	SnapshotSchedule    string
+	SnapshotServiceUser string
+
+	// First day of the week used to number snapshot weeks within a month
+	WeekStartDay time.Weekday
+
+	// Outgoing mail: SMTPTLS is "starttls" (default), "tls" (implicit, e.g. port 465) or
+	// "none" for a local SMTP sink. Without SMTPHost no email is sent.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	SMTPTLS      string
+
+	// Email digest: sent after each snapshot save (on by default) and/or on its own cron schedule (empty = disabled)
+	DigestOnSnapshot bool
+	DigestSchedule   string
+
+	// Users always granted the admin role on login (ADMIN_EMAILS, comma-separated)
+	AdminEmails []string
+
+	// Login restrictions (comma-separated, empty = unrestricted): allowed domains,
+	// emails allowed regardless of domain, and emails that are always rejected
+	AllowedEmailDomains []string
+	AllowedEmails       []string
+	DeniedEmails        []string
+
 	// JWT
-	JWTSecret     string
-	JWTExpiration int // hours
+	JWTSecret       string
+	AccessTokenTTL  time.Duration // Lifetime of access JWTs
+	RefreshTokenTTL time.Duration // Lifetime of a login's refresh-token chain
+	CookieSecure    bool          // Mark the refresh cookie Secure (HTTPS deployments)
 
 	// Frontend
 	FrontendURL string
@@ -57,6 +108,7 @@ func Load() {
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBName:     getEnv("DB_NAME", "weeklyds"),
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
+		SeedMode:   getEnv("SEED_MODE", "missing"),
 
 		// Google OAuth
 		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
@@ -68,9 +120,49 @@ func Load() {
 		SheetName:       getEnv("SHEET_NAME", "DashboardTemplate"),
 		SpreadsheetYear: getEnvInt("SPREADSHEET_YEAR", 2026),
 
+		GoogleServiceAccountKeyFile: getEnv("GOOGLE_SERVICE_ACCOUNT_KEY_FILE", ""),
+
+		// KPI data source
+		KPISource:           getEnv("KPI_SOURCE", "sheets"),
+		KPICacheTTL:         time.Duration(getEnvInt("KPI_CACHE_TTL_SECONDS", 300)) * time.Second,
+		KPICacheStaleWindow: time.Duration(getEnvInt("KPI_CACHE_STALE_SECONDS", 1800)) * time.Second,
+
+		// Overall score
+		OverallScoreMethod: getEnv("OVERALL_SCORE_METHOD", "green_ratio"),
+		AttainmentCeiling:  float64(getEnvInt("ATTAINMENT_CEILING", 100)),
+
+		// Automatic snapshots
+		SnapshotSchedule:    getEnv("SNAPSHOT_SCHEDULE", ""),
+		SnapshotServiceUser: getEnv("SNAPSHOT_SERVICE_USER", ""),
+
+		// Week numbering
+		WeekStartDay: getEnvWeekday("WEEK_START_DAY", time.Monday),
+
+		// Outgoing mail
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "weekly-dashboard@localhost"),
+		SMTPTLS:      strings.ToLower(getEnv("SMTP_TLS", "starttls")),
+
+		// Email digest
+		DigestOnSnapshot: getEnv("DIGEST_ON_SNAPSHOT", "true") == "true",
+		DigestSchedule:   getEnv("DIGEST_SCHEDULE", ""),
+
+		// Roles
+		AdminEmails: getEnvList("ADMIN_EMAILS"),
+
+		// Login restrictions
+		AllowedEmailDomains: getEnvList("ALLOWED_EMAIL_DOMAINS"),
+		AllowedEmails:       getEnvList("ALLOWED_EMAILS"),
+		DeniedEmails:        getEnvList("DENIED_EMAILS"),
+
 		// JWT
-		JWTSecret:     getEnv("JWT_SECRET", "weekly-dashboard-secret-key-change-in-production"),
-		JWTExpiration: getEnvInt("JWT_EXPIRATION_HOURS", 24),
+		JWTSecret:       getEnv("JWT_SECRET", "weekly-dashboard-secret-key-change-in-production"),
+		AccessTokenTTL:  time.Duration(getEnvInt("ACCESS_TOKEN_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL: time.Duration(getEnvInt("REFRESH_TOKEN_DAYS", 14)) * 24 * time.Hour,
+		CookieSecure:    getEnv("COOKIE_SECURE", "false") == "true",
 
 		// Frontend
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
@@ -86,6 +178,93 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList reads a comma-separated list, lowercased with blanks dropped
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Login rejection reasons, passed to the frontend login page as ?error=<reason>
+const (
+	LoginReasonDenied     = "email_denied"
+	LoginReasonDomain     = "domain_not_allowed"
+	LoginReasonNotAllowed = "email_not_allowed"
+	LoginReasonUnverified = "email_unverified"
+)
+
+// LoginRejection applies the configured deny list, allow list and allowed domains, returning
+// why the email may not sign in, or "" if it may. Denied emails are always rejected; with no
+// allow list or domains configured anyone else may sign in. Bootstrap admins count as allow-listed.
+func LoginRejection(email string, verified bool) string {
+	if ContainsFold(AppConfig.DeniedEmails, email) {
+		return LoginReasonDenied
+	}
+	if len(AppConfig.AllowedEmailDomains) == 0 && len(AppConfig.AllowedEmails) == 0 {
+		return ""
+	}
+
+	// Restrictions rely on Google having verified the address
+	if !verified {
+		return LoginReasonUnverified
+	}
+	if ContainsFold(AppConfig.AllowedEmails, email) || IsAdminEmail(email) {
+		return ""
+	}
+
+	if at := strings.LastIndex(email, "@"); at >= 0 && ContainsFold(AppConfig.AllowedEmailDomains, email[at+1:]) {
+		return ""
+	}
+	if len(AppConfig.AllowedEmailDomains) > 0 {
+		return LoginReasonDomain
+	}
+	return LoginReasonNotAllowed
+}
+
+// IsAdminEmail reports whether email is in the bootstrap admin list
+func IsAdminEmail(email string) bool {
+	return ContainsFold(AppConfig.AdminEmails, email)
+}
+
+// ContainsFold reports whether a list read by getEnvList contains value, ignoring case
+func ContainsFold(list []string, value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func getEnvWeekday(key string, defaultValue time.Weekday) time.Weekday {
+	if value := os.Getenv(key); value != "" {
+		if day, ok := ParseWeekday(value); ok {
+			return day
+		}
+		log.Printf("Warning: invalid %s '%s', using %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// ParseWeekday parses an English weekday name ("monday", "Sun", ...)
+func ParseWeekday(value string) (time.Weekday, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), value) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intVal, err := strconv.Atoi(value); err == nil {
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoginRejection(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		email    string
+		verified bool
+		want     string
+	}{
+		{"no restrictions", Config{}, "anyone@gmail.com", true, ""},
+		{"no restrictions, unverified", Config{}, "anyone@gmail.com", false, ""},
+		{"denied without other lists", Config{DeniedEmails: []string{"bad@corp.com"}}, "Bad@Corp.com", true, LoginReasonDenied},
+		{"denied beats allowed", Config{DeniedEmails: []string{"a@corp.com"}, AllowedEmails: []string{"a@corp.com"}}, "a@corp.com", true, LoginReasonDenied},
+		{"denied admin", Config{DeniedEmails: []string{"boss@corp.com"}, AdminEmails: []string{"boss@corp.com"}}, "boss@corp.com", true, LoginReasonDenied},
+		{"allowed domain", Config{AllowedEmailDomains: []string{"corp.com"}}, "jo@CORP.com", true, ""},
+		{"subdomain is another domain", Config{AllowedEmailDomains: []string{"corp.com"}}, "jo@eu.corp.com", true, LoginReasonDomain},
+		{"other domain", Config{AllowedEmailDomains: []string{"corp.com"}}, "jo@gmail.com", true, LoginReasonDomain},
+		{"unverified with restrictions", Config{AllowedEmailDomains: []string{"corp.com"}}, "jo@corp.com", false, LoginReasonUnverified},
+		{"allow-listed outside the domains", Config{AllowedEmailDomains: []string{"corp.com"}, AllowedEmails: []string{"partner@gmail.com"}}, "partner@gmail.com", true, ""},
+		{"not on the allow list", Config{AllowedEmails: []string{"a@corp.com"}}, "b@corp.com", true, LoginReasonNotAllowed},
+		{"admin counts as allow-listed", Config{AllowedEmails: []string{"a@corp.com"}, AdminEmails: []string{"boss@gmail.com"}}, "boss@gmail.com", true, ""},
+		{"no at sign", Config{AllowedEmailDomains: []string{"corp.com"}}, "corp.com", true, LoginReasonDomain},
+	}
+
+	saved := AppConfig
+	defer func() { AppConfig = saved }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			AppConfig = &cfg
+			if got := LoginRejection(tt.email, tt.verified); got != tt.want {
+				t.Errorf("LoginRejection(%q, %v) = %q, want %q", tt.email, tt.verified, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{" , ,", nil},
+		{"A@Corp.com", []string{"a@corp.com"}},
+		{" a@corp.com , B@corp.com,,", []string{"a@corp.com", "b@corp.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_EMAIL_LIST", tt.value)
+		if got := getEnvList("TEST_EMAIL_LIST"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("getEnvList(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"monday", "Monday", true},
+		{" Sun ", "Sunday", true},
+		{"THU", "Thursday", true},
+		{"mo", "", false},
+		{"funday", "", false},
+	}
+
+	for _, tt := range tests {
+		day, ok := ParseWeekday(tt.value)
+		if ok != tt.wantOK || (ok && day.String() != tt.want) {
+			t.Errorf("ParseWeekday(%q) = %s, %v, want %s, %v", tt.value, day, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -30,6 +30,8 @@ func Connect() error {
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// Report constraint violations as gorm.ErrDuplicatedKey etc.
+		TranslateError: true,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
@@ -59,15 +61,123 @@ func Migrate() error {
 		&models.WeeklySnapshot{},
 		&models.Screenshot{},
 		&models.AppSetting{},
+		&models.UploadedWorkbook{},
+		&models.StatusThreshold{},
+		&models.DepartmentWeight{},
+		&models.SnapshotRun{},
+		&models.YearSpreadsheet{},
+		&models.LoginAudit{},
+		&models.RevokedToken{},
+		&models.RefreshToken{},
+		&models.APIToken{},
+		&models.AuditEvent{},
+		&models.WeeklyReport{},
+		&models.DigestRecipient{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.AlertRule{},
+		&models.Alert{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Backfill ISO week numbers of snapshots saved before they were stored
+	result := DB.Exec(`UPDATE weekly_snapshots
+		SET iso_year = EXTRACT(ISOYEAR FROM snapshot_date), iso_week = EXTRACT(WEEK FROM snapshot_date)
+		WHERE iso_week = 0`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill snapshot ISO weeks: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled ISO week for %d snapshots", result.RowsAffected)
+	}
+
+	if err := ensureSnapshotVersionIndex(); err != nil {
+		return err
+	}
+	if err := ensureWeeklyReportIndex(); err != nil {
+		return err
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
+// SnapshotVersionIndex makes (indicator, year, month, week, version) unique across all
+// snapshot rows, soft-deleted ones included, so concurrent saves cannot duplicate a version
+const SnapshotVersionIndex = "idx_weekly_snapshots_version"
+
+// ensureSnapshotVersionIndex creates the unique snapshot version index. Rows saved before
+// versioning all carry version 1, so their versions are first renumbered in save order.
+func ensureSnapshotVersionIndex() error {
+	if DB.Migrator().HasIndex(&models.WeeklySnapshot{}, SnapshotVersionIndex) {
+		return nil
+	}
+
+	result := DB.Exec(`UPDATE weekly_snapshots AS w SET version = r.version
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (
+				PARTITION BY indicator_id, year, month, week_number ORDER BY version, id
+			) AS version
+			FROM weekly_snapshots
+		) AS r
+		WHERE w.id = r.id AND w.version <> r.version`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to renumber snapshot versions: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Renumbered versions of %d legacy snapshots", result.RowsAffected)
+	}
+
+	// Only the newest of any live duplicates stays current
+	if err := DB.Exec(`UPDATE weekly_snapshots AS w SET is_current = false
+		WHERE w.deleted_at IS NULL AND w.is_current AND EXISTS (
+			SELECT 1 FROM weekly_snapshots AS n
+			WHERE n.deleted_at IS NULL AND n.indicator_id = w.indicator_id AND n.year = w.year
+				AND n.month = w.month AND n.week_number = w.week_number AND n.version > w.version
+		)`).Error; err != nil {
+		return fmt.Errorf("failed to mark superseded snapshots: %w", err)
+	}
+
+	if err := DB.Exec("CREATE UNIQUE INDEX " + SnapshotVersionIndex +
+		" ON weekly_snapshots (indicator_id, year, month, week_number, version)").Error; err != nil {
+		return fmt.Errorf("failed to create snapshot version index: %w", err)
+	}
+	return nil
+}
+
+// WeeklyReportWeekIndex makes (month, year, week) unique across weekly reports, so concurrent
+// saves of a week replace its report instead of adding a second one
+const WeeklyReportWeekIndex = "idx_weekly_reports_week"
+
+// ensureWeeklyReportIndex creates the unique weekly report index. Reports are hard-deleted from
+// now on, so soft-deleted ones and all but the newest duplicate of a week are removed first.
+func ensureWeeklyReportIndex() error {
+	if DB.Migrator().HasIndex(&models.WeeklyReport{}, WeeklyReportWeekIndex) {
+		return nil
+	}
+
+	result := DB.Exec(`DELETE FROM weekly_reports AS r
+		WHERE r.deleted_at IS NOT NULL OR EXISTS (
+			SELECT 1 FROM weekly_reports AS n
+			WHERE n.deleted_at IS NULL AND n.month = r.month AND n.year = r.year AND n.week = r.week
+				AND (n.generated_at, n.id) > (r.generated_at, r.id)
+		)`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove duplicate weekly reports: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Removed %d deleted or duplicate weekly reports", result.RowsAffected)
+	}
+
+	if err := DB.Exec("CREATE UNIQUE INDEX " + WeeklyReportWeekIndex +
+		" ON weekly_reports (month, year, week)").Error; err != nil {
+		return fmt.Errorf("failed to create weekly report index: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func Close() error {
 	sqlDB, err := DB.DB()
@@ -3,9 +3,19 @@ package database
 import (
 	"log"
 
+	"weekly-dashboard/config"
 	"weekly-dashboard/models"
 )
 
+// Indicator seed modes (SEED_MODE)
+const (
+	// SeedModeMissing only inserts default indicators that do not exist yet,
+	// leaving indicators edited or deleted through the API untouched
+	SeedModeMissing = "missing"
+	// SeedModeSync overwrites existing indicators with the built-in defaults
+	SeedModeSync = "sync"
+)
+
 // Seed populates the database with initial data
 func Seed() error {
 	log.Println("Seeding database...")
@@ -15,17 +25,76 @@ func Seed() error {
 		return err
 	}
 
+	// Seed status thresholds
+	if err := seedThresholds(); err != nil {
+		return err
+	}
+
+	// Promote bootstrap admins that already have an account
+	if err := seedAdminRoles(); err != nil {
+		return err
+	}
+
 	log.Println("Database seeding completed successfully")
 	return nil
 }
 
+// seedAdminRoles grants the admin role to existing users listed in ADMIN_EMAILS. Users created
+// before roles existed were migrated to viewer, so when no admin is left the earliest user is
+// promoted; otherwise nobody could reach the admin routes.
+func seedAdminRoles() error {
+	if len(config.AppConfig.AdminEmails) == 0 {
+		log.Println("Warning: ADMIN_EMAILS is empty, no bootstrap admins configured")
+	} else {
+		result := DB.Model(&models.User{}).
+			Where("LOWER(email) IN ? AND role <> ?", config.AppConfig.AdminEmails, models.RoleAdmin).
+			Update("role", models.RoleAdmin)
+		if result.Error != nil {
+			log.Printf("Failed to grant bootstrap admin roles: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Granted admin role to %d bootstrap admin(s)", result.RowsAffected)
+		}
+	}
+
+	var admins int64
+	if err := DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+		log.Printf("Failed to count admins: %v", err)
+		return err
+	}
+	if admins > 0 {
+		return nil
+	}
+
+	var earliest models.User
+	result := DB.Order("created_at, id").Limit(1).Find(&earliest)
+	if result.Error != nil {
+		log.Printf("Failed to find the earliest user: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		log.Println("WARNING: no admin exists and ADMIN_EMAILS matches no user yet; " +
+			"set ADMIN_EMAILS so the first admin is granted the role when they sign in")
+		return nil
+	}
+	if err := DB.Model(&earliest).Update("role", models.RoleAdmin).Error; err != nil {
+		log.Printf("Failed to promote %s to admin: %v", earliest.Email, err)
+		return err
+	}
+	log.Printf("WARNING: no admin existed, promoted the earliest user %s to admin; set ADMIN_EMAILS to choose admins explicitly", earliest.Email)
+	return nil
+}
+
 func seedIndicators() error {
 	indicators := models.GetDefaultIndicators()
+	syncExisting := config.AppConfig.SeedMode == SeedModeSync
+	log.Printf("Seeding indicators (mode=%s)", config.AppConfig.SeedMode)
 
 	for _, indicator := range indicators {
-		// Check if indicator already exists
+		// Check if indicator already exists (including deleted ones, so they are not re-created)
 		var existing models.Indicator
-		result := DB.Where("code = ?", indicator.Code).First(&existing)
+		result := DB.Unscoped().Where("code = ?", indicator.Code).First(&existing)
 
 		if result.RowsAffected == 0 {
 			// Create new indicator
@@ -34,7 +103,7 @@ func seedIndicators() error {
 				return err
 			}
 			log.Printf("Seeded indicator: %s - %s", indicator.Code, indicator.Name)
-		} else {
+		} else if syncExisting && !existing.DeletedAt.Valid {
 			// Update existing indicator
 			existing.Department = indicator.Department
 			existing.Name = indicator.Name
@@ -55,3 +124,22 @@ func seedIndicators() error {
 
 	return nil
 }
+
+// seedThresholds inserts the global threshold profiles if they do not exist yet
+func seedThresholds() error {
+	for _, threshold := range models.GetDefaultThresholds() {
+		var count int64
+		DB.Model(&models.StatusThreshold{}).Where("scope = ?", threshold.Scope).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		if err := DB.Create(&threshold).Error; err != nil {
+			log.Printf("Failed to seed %s threshold: %v", threshold.Scope, err)
+			return err
+		}
+		log.Printf("Seeded %s threshold: %.0f/%.0f/%.0f", threshold.Scope, threshold.Upper, threshold.Middle, threshold.Lower)
+	}
+
+	return nil
+}
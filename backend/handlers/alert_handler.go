@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertHandler handles alert rule and alert endpoints
+type AlertHandler struct {
+	alertService *services.AlertService
+}
+
+// NewAlertHandler creates a new AlertHandler instance
+func NewAlertHandler(alertService *services.AlertService) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+	}
+}
+
+// AlertRuleRequest represents the request to create or update an alert rule
+type AlertRuleRequest struct {
+	Name          string  `json:"name"`
+	IndicatorCode string  `json:"indicator_code"` // Empty = every indicator (of the department, if set)
+	Department    string  `json:"department"`     // Empty = every department
+	Condition     string  `json:"condition"`
+	Threshold     float64 `json:"threshold"`   // percentage_below, variance_below: in %; wow_drop: in percentage points
+	FromStatus    string  `json:"from_status"` // status_transition, optional
+	ToStatus      string  `json:"to_status"`   // status_transition
+	Weeks         int     `json:"weeks"`       // consecutive_red
+	IsActive      *bool   `json:"is_active"`   // Default true
+}
+
+// ListAlerts returns alerts, firing ones first
+// @Summary List alerts
+// @Description Returns the alerts opened by the alert rules, firing ones first and then newest first.
+// @Description Rules are evaluated whenever the current month's dashboard is computed or a snapshot is saved.
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param state query string false "firing or resolved"
+// @Param rule_id query int false "Only alerts of this rule"
+// @Param indicator query string false "Only alerts of this indicator code"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Alerts per page (max 200)" default(50)
+// @Success 200 {object} map[string]interface{} "Alerts"
+// @Router /api/v1/alerts [get]
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	pageSize := 50
+	if s, err := strconv.Atoi(c.Query("page_size")); err == nil && s > 0 && s <= 200 {
+		pageSize = s
+	}
+
+	var ruleID uint
+	if idStr := c.Query("rule_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid rule ID",
+			})
+			return
+		}
+		ruleID = uint(id)
+	}
+
+	state := c.Query("state")
+	if state != "" && state != models.AlertFiring && state != models.AlertResolved {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "State must be firing or resolved",
+		})
+		return
+	}
+
+	indicator := strings.TrimSpace(c.Query("indicator"))
+	alerts, total, err := services.ListAlerts(state, ruleID, indicator, page, pageSize)
+	if err != nil {
+		log.Printf("Failed to list alerts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch alerts",
+		})
+		return
+	}
+
+	var firing int64
+	database.DB.Model(&models.Alert{}).Where("state = ?", models.AlertFiring).Count(&firing)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"alerts":    alerts,
+			"firing":    firing,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// ListAlertRules returns all alert rules
+// @Summary List alert rules
+// @Description Returns the alert rules and the available conditions
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Alert rules"
+// @Router /api/v1/alerts/rules [get]
+func (h *AlertHandler) ListAlertRules(c *gin.Context) {
+	var rules []models.AlertRule
+	if err := database.DB.Order("id ASC").Find(&rules).Error; err != nil {
+		log.Printf("Failed to list alert rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch alert rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"rules":      rules,
+			"conditions": models.AlertConditions,
+			"statuses":   services.AlertStatuses,
+		},
+	})
+}
+
+// CreateAlertRule creates an alert rule
+// @Summary Create alert rule
+// @Description Creates a rule for one indicator, one department or all indicators. Conditions:
+// @Description status_transition (to_status, optional from_status), percentage_below (threshold),
+// @Description variance_below (threshold), wow_drop (threshold in points) and consecutive_red (weeks).
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AlertRuleRequest true "Rule definition"
+// @Success 201 {object} map[string]interface{} "Created alert rule"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/alerts/rules [post]
+func (h *AlertHandler) CreateAlertRule(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	rule := models.AlertRule{IsActive: true, CreatedBy: user.Email}
+	if !applyAlertRuleRequest(c, &rule, &req) {
+		return
+	}
+
+	if err := database.DB.Create(&rule).Error; err != nil {
+		log.Printf("Failed to create alert rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to create alert rule",
+		})
+		return
+	}
+	// IsActive defaults to true in the database, so an explicit false is written separately
+	if !rule.IsActive {
+		database.DB.Model(&rule).Update("is_active", false)
+	}
+
+	recordAudit(c, models.AuditAlertRuleCreate, "alert_rule", strconv.Itoa(int(rule.ID)), nil, rule)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Alert rule created",
+		"data":    rule,
+	})
+}
+
+// UpdateAlertRule updates an alert rule
+// @Summary Update alert rule
+// @Description Changes a rule. Its firing alerts are resolved when the rule is deactivated or its
+// @Description condition or scope changes, and fire again on the next evaluation if still breached.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Alert rule ID"
+// @Param request body AlertRuleRequest true "Rule definition"
+// @Success 200 {object} map[string]interface{} "Alert rule updated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/alerts/rules/{id} [put]
+func (h *AlertHandler) UpdateAlertRule(c *gin.Context) {
+	rule, ok := findAlertRule(c)
+	if !ok {
+		return
+	}
+
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	before := *rule
+	if !applyAlertRuleRequest(c, rule, &req) {
+		return
+	}
+
+	if err := database.DB.Save(rule).Error; err != nil {
+		log.Printf("Failed to update alert rule %d: %v", rule.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to update alert rule",
+		})
+		return
+	}
+
+	definition := before
+	definition.Name, definition.IsActive, definition.UpdatedAt = rule.Name, rule.IsActive, rule.UpdatedAt
+	if !rule.IsActive || definition != *rule {
+		h.alertService.ResolveRule(rule)
+	}
+
+	recordAudit(c, models.AuditAlertRuleUpdate, "alert_rule", strconv.Itoa(int(rule.ID)), before, rule)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Alert rule updated",
+		"data":    rule,
+	})
+}
+
+// DeleteAlertRule deletes an alert rule; its firing alerts are resolved and kept in the listing
+// @Summary Delete alert rule
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} map[string]interface{} "Alert rule deleted"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/alerts/rules/{id} [delete]
+func (h *AlertHandler) DeleteAlertRule(c *gin.Context) {
+	rule, ok := findAlertRule(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Delete(rule).Error; err != nil {
+		log.Printf("Failed to delete alert rule %d: %v", rule.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete alert rule",
+		})
+		return
+	}
+	h.alertService.ResolveRule(rule)
+
+	recordAudit(c, models.AuditAlertRuleDelete, "alert_rule", strconv.Itoa(int(rule.ID)), rule, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Alert rule deleted",
+	})
+}
+
+// findAlertRule loads the rule named by the id path parameter,
+// writing an error response if it is invalid or missing
+func findAlertRule(c *gin.Context) (*models.AlertRule, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid alert rule ID",
+		})
+		return nil, false
+	}
+
+	var rule models.AlertRule
+	if err := database.DB.First(&rule, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Alert rule not found",
+		})
+		return nil, false
+	}
+	return &rule, true
+}
+
+// applyAlertRuleRequest validates a request and copies it onto a rule,
+// writing a bad request response if it is invalid
+func applyAlertRuleRequest(c *gin.Context, rule *models.AlertRule, req *AlertRuleRequest) bool {
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.IndicatorCode = strings.TrimSpace(req.IndicatorCode)
+	rule.Department = strings.ToUpper(strings.TrimSpace(req.Department))
+	rule.Condition = strings.TrimSpace(req.Condition)
+	rule.Threshold = req.Threshold
+	rule.FromStatus = strings.ToLower(strings.TrimSpace(req.FromStatus))
+	rule.ToStatus = strings.ToLower(strings.TrimSpace(req.ToStatus))
+	rule.Weeks = req.Weeks
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	// Parameters of other conditions are cleared so the stored rule reads unambiguously
+	if rule.Condition != models.AlertStatusTransition {
+		rule.FromStatus, rule.ToStatus = "", ""
+	}
+	if rule.Condition != models.AlertConsecutiveRed {
+		rule.Weeks = 0
+	}
+	if rule.Condition == models.AlertStatusTransition || rule.Condition == models.AlertConsecutiveRed {
+		rule.Threshold = 0
+	}
+
+	if len(rule.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Name must be at most 100 characters",
+		})
+		return false
+	}
+	if err := services.ValidateAlertRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid alert rule: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
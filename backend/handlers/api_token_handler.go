@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APITokenHandler handles personal API token endpoints
+type APITokenHandler struct {
+	tokenService *services.APITokenService
+	kpiSource    services.KPISource
+}
+
+// NewAPITokenHandler creates a new APITokenHandler instance
+func NewAPITokenHandler(tokenService *services.APITokenService, kpiSource services.KPISource) *APITokenHandler {
+	return &APITokenHandler{
+		tokenService: tokenService,
+		kpiSource:    kpiSource,
+	}
+}
+
+// CreateAPITokenRequest represents the request to create an API token
+type CreateAPITokenRequest struct {
+	Name          string `json:"name"`
+	Scope         string `json:"scope"`           // read (default) or write
+	ExpiresInDays int    `json:"expires_in_days"` // 1-365, default 90
+}
+
+// ListTokens returns the current user's API tokens
+// @Summary List API tokens
+// @Description Returns the current user's personal API tokens (without their secret values)
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "API tokens"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /api/v1/tokens [get]
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	tokens, err := h.tokenService.ListTokens(user.ID)
+	if err != nil {
+		log.Printf("Failed to list API tokens of %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch API tokens",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tokens,
+	})
+}
+
+// CreateToken issues a new API token for the current user
+// @Summary Create API token
+// @Description Creates a personal API token for scripted access. The token value is only returned once.
+// @Description Read-scoped tokens may only make GET requests; write-scoped tokens act with the user's role.
+// @Description Only users with access to the current year's performance spreadsheet may create tokens.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPITokenRequest true "Token name, scope and lifetime"
+// @Success 201 {object} map[string]interface{} "Created token with its value"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "No access to the performance spreadsheet"
+// @Router /api/v1/tokens [post]
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Name is required and must be at most 100 characters",
+		})
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = models.ScopeRead
+	}
+	if !models.IsValidScope(req.Scope) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Scope must be read or write",
+		})
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = services.DefaultAPITokenDays
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > services.MaxAPITokenDays {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "expires_in_days must be between 1 and " + strconv.Itoa(services.MaxAPITokenDays),
+		})
+		return
+	}
+
+	// A token reads with its owner's access, so users without it cannot create one
+	if !checkSourceAccess(c, h.kpiSource, user, time.Now().Year()) {
+		return
+	}
+
+	token, raw, err := h.tokenService.CreateToken(user, req.Name, req.Scope, req.ExpiresInDays)
+	if err != nil {
+		log.Printf("Failed to create API token for %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to create API token",
+		})
+		return
+	}
+
+	log.Printf("User %s created %s API token %q (%s)", user.Email, token.Scope, token.Name, token.Prefix)
+	recordAudit(c, models.AuditAPITokenCreate, "api_token", strconv.Itoa(int(token.ID)), nil, token)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Copy the token now; it will not be shown again",
+		"data": gin.H{
+			"token":     raw,
+			"api_token": token,
+		},
+	})
+}
+
+// RevokeToken revokes one of the current user's API tokens
+// @Summary Revoke API token
+// @Description Revokes a personal API token; requests using it are rejected from then on
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API token ID"
+// @Success 200 {object} map[string]interface{} "Token revoked"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/tokens/{id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid token ID",
+		})
+		return
+	}
+
+	token, err := h.tokenService.RevokeToken(user.ID, uint(id))
+	if errors.Is(err, services.ErrAPITokenNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "API token not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to revoke API token %d of %s: %v", id, user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to revoke API token",
+		})
+		return
+	}
+
+	log.Printf("User %s revoked API token %q (%s)", user.Email, token.Name, token.Prefix)
+	recordAudit(c, models.AuditAPITokenRevoke, "api_token", strconv.FormatUint(id, 10), nil, token)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "API token revoked",
+		"data":    token,
+	})
+}
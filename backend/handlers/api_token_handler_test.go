@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"weekly-dashboard/services"
+)
+
+func TestCreateTokenValidation(t *testing.T) {
+	user := testUser(2, "outsider@gmail.com")
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"invalid JSON", `{`, http.StatusBadRequest},
+		{"missing name", `{"scope":"read"}`, http.StatusBadRequest},
+		{"unknown scope", `{"name":"ci","scope":"admin"}`, http.StatusBadRequest},
+		{"lifetime too long", `{"name":"ci","expires_in_days":366}`, http.StatusBadRequest},
+		// A valid request from a user without spreadsheet access is refused before any token exists
+		{"no spreadsheet access", `{"name":"ci","scope":"read"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &stubSource{denied: map[uint]bool{user.ID: true}}
+			handler := NewAPITokenHandler(services.NewAPITokenService(), source)
+			c, w := newTestContext(http.MethodPost, tt.body, user, nil)
+
+			handler.CreateToken(c)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
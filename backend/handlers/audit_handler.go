@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AuditHandler handles audit log endpoints
+type AuditHandler struct{}
+
+// NewAuditHandler creates a new AuditHandler instance
+func NewAuditHandler() *AuditHandler {
+	return &AuditHandler{}
+}
+
+// recordAudit writes an audit event for the authenticated user. before and after are
+// summaries of the target (nil when it did not exist or was removed). Failures are only
+// logged so they never fail the audited action.
+func recordAudit(c *gin.Context, action, targetType, targetID string, before, after interface{}) {
+	user, _ := middleware.GetCurrentUser(c)
+	recordAuditAs(c, user, action, targetType, targetID, before, after)
+}
+
+// recordAuditAs writes an audit event for an explicit actor, e.g. during sign-in
+func recordAuditAs(c *gin.Context, actor *models.User, action, targetType, targetID string, before, after interface{}) {
+	event := models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     auditSummary(before),
+		After:      auditSummary(after),
+		IPAddress:  c.ClientIP(),
+		CreatedAt:  time.Now(),
+	}
+	if actor != nil {
+		event.ActorID = &actor.ID
+		event.ActorEmail = actor.Email
+	}
+	if token, ok := middleware.GetCurrentAPIToken(c); ok {
+		event.APITokenID = &token.ID
+	}
+
+	if err := database.DB.Create(&event).Error; err != nil {
+		log.Printf("Warning: Failed to record audit event %s on %s %s: %v", action, targetType, targetID, err)
+	}
+}
+
+// auditSummary encodes a before/after summary as JSON, or "" for nil
+func auditSummary(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Warning: Failed to encode audit summary: %v", err)
+		return ""
+	}
+	// Typed nil pointers encode as null
+	if string(data) == "null" {
+		return ""
+	}
+	return string(data)
+}
+
+// weekTarget identifies a week of a month in audit events, e.g. "2025-03/week-2"
+func weekTarget(month, year, week int) string {
+	return fmt.Sprintf("%d-%02d/week-%d", year, month, week)
+}
+
+// ListAuditEvents returns audit events, newest first
+// @Summary List audit events
+// @Description Returns a page of audit events, newest first, optionally filtered (admin only)
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param actor query string false "Actor email"
+// @Param action query string false "Action, or an action prefix ending in '.' (e.g. settings.)"
+// @Param target_type query string false "Target type (snapshot, screenshot, setting, ...)"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "Earliest date (YYYY-MM-DD)"
+// @Param to query string false "Latest date (YYYY-MM-DD), inclusive"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Events per page (max 200)" default(50)
+// @Success 200 {object} map[string]interface{} "Audit events"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) ListAuditEvents(c *gin.Context) {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	pageSize := 50
+	if s, err := strconv.Atoi(c.Query("page_size")); err == nil && s > 0 && s <= 200 {
+		pageSize = s
+	}
+
+	query := database.DB.Model(&models.AuditEvent{})
+	if actor := strings.TrimSpace(c.Query("actor")); actor != "" {
+		query = query.Where("LOWER(actor_email) = ?", strings.ToLower(actor))
+	}
+	if action := c.Query("action"); action != "" {
+		if strings.HasSuffix(action, ".") {
+			query = query.Where("action LIKE ?", action+"%")
+		} else {
+			query = query.Where("action = ?", action)
+		}
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.ParseInLocation("2006-01-02", fromStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid from date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.ParseInLocation("2006-01-02", toStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid to date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	// Shared by the count and the page query
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Printf("Failed to count audit events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch audit events",
+		})
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := query.Order("created_at desc, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&events).Error; err != nil {
+		log.Printf("Failed to list audit events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch audit events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"events":    events,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
@@ -3,15 +3,27 @@ package handlers
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
+	"time"
 
 	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
 	"weekly-dashboard/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Refresh-token cookie, only sent to the auth routes
+const (
+	refreshCookieName = "refresh_token"
+	refreshCookiePath = "/api/v1/auth"
+)
+
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
 	authService *services.AuthService
@@ -56,7 +68,7 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 // @Produce json
 // @Param code query string true "Authorization code from Google"
 // @Param state query string true "OAuth state parameter"
-// @Success 302 {string} string "Redirect to frontend with token"
+// @Success 302 {string} string "Redirect to frontend; sets the refresh-token cookie"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/auth/callback [get]
@@ -108,8 +120,15 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 		return
 	}
 
-	// Create or update user in database
+	// Create or update user in database (rejects accounts outside the allowed domains/lists)
 	user, err := h.authService.CreateOrUpdateUser(userInfo, token)
+	var rejected *services.LoginRejectedError
+	if errors.As(err, &rejected) {
+		log.Printf("Rejected login: %v", err)
+		recordLogin(c, userInfo.Email, models.LoginRejected, rejected.Reason)
+		c.Redirect(http.StatusTemporaryRedirect, config.AppConfig.FrontendURL+"/login?error="+url.QueryEscape(rejected.Reason))
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to create/update user: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -119,41 +138,213 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	jwtToken, err := h.authService.GenerateJWT(user)
+	// Start a session: the refresh token goes into an HttpOnly cookie and the
+	// frontend exchanges it for an access token via POST /auth/refresh
+	refreshToken, _, err := h.authService.StartSession(user, c.ClientIP())
 	if err != nil {
-		log.Printf("Failed to generate JWT: %v", err)
+		log.Printf("Failed to start session: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to generate authentication token",
 		})
 		return
 	}
+	setRefreshCookie(c, refreshToken)
+
+	recordLogin(c, user.Email, models.LoginAccepted, "")
+	recordAuditAs(c, user, models.AuditAuthLogin, "user", user.Email, nil, gin.H{"role": user.Role})
 
-	// Redirect to frontend with token
-	frontendURL := config.AppConfig.FrontendURL
-	redirectURL := frontendURL + "/auth/callback?token=" + jwtToken
+	// Redirect to frontend (no token in the URL)
+	redirectURL := config.AppConfig.FrontendURL + "/auth/callback"
 	log.Printf("Authentication successful for user: %s, redirecting to: %s", user.Email, redirectURL)
 	c.Redirect(http.StatusTemporaryRedirect, redirectURL)
 }
 
+// Refresh exchanges the refresh-token cookie for a new access token
+// @Summary Refresh access token
+// @Description Rotates the refresh-token cookie and returns a short-lived access token. Reusing a rotated refresh token revokes the whole session.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Access token"
+// @Failure 401 {object} map[string]interface{} "Missing, invalid or reused refresh token"
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	raw, err := c.Cookie(refreshCookieName)
+	if err != nil || raw == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "No active session",
+		})
+		return
+	}
+
+	user, next, sessionID, err := h.authService.RotateRefreshToken(raw, c.ClientIP())
+	if err != nil {
+		clearRefreshCookie(c)
+
+		message := "Session expired, please sign in again"
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			message = "Session was revoked because its refresh token was reused, please sign in again"
+		} else if !errors.Is(err, services.ErrRefreshTokenInvalid) {
+			log.Printf("Failed to refresh session: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   message,
+		})
+		return
+	}
+	setRefreshCookie(c, next)
+
+	accessToken, err := h.authService.GenerateJWT(user, sessionID)
+	if err != nil {
+		log.Printf("Failed to generate JWT: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to generate authentication token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"access_token": accessToken,
+			"token_type":   "Bearer",
+			"expires_in":   int(config.AppConfig.AccessTokenTTL.Seconds()),
+		},
+	})
+}
+
+// setRefreshCookie stores the refresh token in an HttpOnly cookie scoped to the auth routes
+func setRefreshCookie(c *gin.Context, token string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(refreshCookieName, token, int(config.AppConfig.RefreshTokenTTL.Seconds()),
+		refreshCookiePath, "", config.AppConfig.CookieSecure, true)
+}
+
+// clearRefreshCookie removes the refresh-token cookie
+func clearRefreshCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(refreshCookieName, "", -1, refreshCookiePath, "", config.AppConfig.CookieSecure, true)
+}
+
+// recordLogin writes a login attempt to the login audit table
+func recordLogin(c *gin.Context, email, outcome, reason string) {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	entry := models.LoginAudit{
+		Email:       email,
+		Outcome:     outcome,
+		Reason:      reason,
+		IPAddress:   c.ClientIP(),
+		UserAgent:   userAgent,
+		AttemptedAt: time.Now(),
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		log.Printf("Warning: Failed to record login audit for %s: %v", email, err)
+	}
+}
+
 // Logout handles user logout
 // @Summary Logout user
-// @Description Clears user session
+// @Description Revokes the current JWT and the user's stored Google refresh token
 // @Tags auth
 // @Produce json
+// @Security BearerAuth
 // @Success 200 {object} map[string]interface{} "Logout successful"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Clear cookies if any
 	c.SetCookie("oauth_state", "", -1, "/", "", false, true)
 
+	user, ok := middleware.GetCurrentUser(c)
+	claims, hasClaims := middleware.GetCurrentClaims(c)
+	if !ok || !hasClaims {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.authService.RevokeJWT(claims); err != nil {
+		log.Printf("Failed to revoke session of %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to log out",
+		})
+		return
+	}
+
+	// End the refresh-token family of this session (from the token, or the cookie for older tokens)
+	sessionID := claims.SessionID
+	if raw, err := c.Cookie(refreshCookieName); err == nil && sessionID == "" {
+		sessionID = h.authService.RefreshTokenFamily(raw)
+	}
+	if err := h.authService.RevokeSession(sessionID); err != nil {
+		log.Printf("Warning: Failed to revoke refresh tokens of %s: %v", user.Email, err)
+	}
+	clearRefreshCookie(c)
+
+	// The session is gone either way; a failed Google revocation is only logged
+	if err := h.authService.RevokeGoogleToken(c.Request.Context(), user); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	log.Printf("User %s logged out", user.Email)
+	recordAudit(c, models.AuditAuthLogout, "user", user.Email, nil, nil)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Logged out successfully",
 	})
 }
 
+// LogoutAll handles logging out every session of the user
+// @Summary Logout all sessions
+// @Description Invalidates every JWT and personal API token issued to the user and revokes the stored Google refresh token
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Logout successful"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.authService.RevokeAllSessions(user); err != nil {
+		log.Printf("Failed to revoke sessions of %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to log out all sessions",
+		})
+		return
+	}
+	clearRefreshCookie(c)
+
+	if err := h.authService.RevokeGoogleToken(c.Request.Context(), user); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	log.Printf("User %s logged out all sessions", user.Email)
+	recordAudit(c, models.AuditAuthLogoutAll, "user", user.Email, nil, nil)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "All sessions logged out",
+	})
+}
+
 // GetCurrentUser returns the current authenticated user
 // @Summary Get current user
 // @Description Returns current authenticated user information
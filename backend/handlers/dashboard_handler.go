@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
 	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
 	"weekly-dashboard/services"
 
 	"github.com/gin-gonic/gin"
@@ -15,14 +19,16 @@ import (
 // DashboardHandler handles dashboard endpoints
 type DashboardHandler struct {
 	dashboardService *services.DashboardService
-	sheetsService    *services.SheetsService
+	kpiSource        services.KPISource
+	digestService    *services.DigestService
 }
 
 // NewDashboardHandler creates a new DashboardHandler instance
-func NewDashboardHandler(dashboardService *services.DashboardService, sheetsService *services.SheetsService) *DashboardHandler {
+func NewDashboardHandler(dashboardService *services.DashboardService, kpiSource services.KPISource, digestService *services.DigestService) *DashboardHandler {
 	return &DashboardHandler{
 		dashboardService: dashboardService,
-		sheetsService:    sheetsService,
+		kpiSource:        kpiSource,
+		digestService:    digestService,
 	}
 }
 
@@ -34,7 +40,8 @@ func NewDashboardHandler(dashboardService *services.DashboardService, sheetsServ
 // @Security BearerAuth
 // @Param month query int false "Month (1-12)" default(current month)
 // @Param year query int false "Year" default(current year)
-// @Success 200 {object} map[string]interface{} "Dashboard data"
+// @Param refresh query bool false "Bypass the server-side layout and KPI caches"
+// @Success 200 {object} map[string]interface{} "Dashboard data (data_source tells whether values are live or cached)"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/dashboard [get]
@@ -67,19 +74,14 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 
 	log.Printf("Fetching dashboard for user %s, month=%d, year=%d", user.Email, month, year)
 
-	// Invalidate layout cache if refresh is requested
+	// Invalidate layout and KPI caches if refresh is requested
 	if c.Query("refresh") == "true" {
-		log.Printf("Force refresh requested, invalidating layout cache")
-		h.sheetsService.InvalidateLayout()
+		log.Printf("Force refresh requested, invalidating layout and KPI caches")
+		h.kpiSource.InvalidateLayout()
 	}
 
 	// Test spreadsheet access first
-	if err := h.sheetsService.TestConnection(c.Request.Context(), user); err != nil {
-		log.Printf("User %s does not have access to spreadsheet: %v", user.Email, err)
-		c.JSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error":   "You do not have access to the performance spreadsheet. Please contact your administrator.",
-		})
+	if !checkSourceAccess(c, h.kpiSource, user, year) {
 		return
 	}
 
@@ -229,9 +231,13 @@ func (h *DashboardHandler) CompareDashboard(c *gin.Context) {
 // @Security BearerAuth
 // @Param month query int false "Month (1-12)" default(current month)
 // @Param year query int false "Year" default(current year)
-// @Param week query int false "Week number (1-5)" default(1)
+// @Param date query string false "Snapshot date (YYYY-MM-DD) within the month; the week is derived from it" default(today)
+// @Param week query int false "Backfill a week of the month (1-6) when no date is given"
 // @Success 200 {object} map[string]interface{} "Snapshot saved"
+// @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Concurrent save"
+// @Failure 422 {object} map[string]interface{} "No indicator data to save"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/dashboard/snapshot [post]
 func (h *DashboardHandler) SaveSnapshot(c *gin.Context) {
@@ -248,7 +254,6 @@ func (h *DashboardHandler) SaveSnapshot(c *gin.Context) {
 	now := time.Now()
 	month := int(now.Month())
 	year := now.Year()
-	weekNumber := 1 // Default to week 1
 
 	if monthStr := c.Query("month"); monthStr != "" {
 		if m, err := strconv.Atoi(monthStr); err == nil && m >= 1 && m <= 12 {
@@ -262,14 +267,38 @@ func (h *DashboardHandler) SaveSnapshot(c *gin.Context) {
 		}
 	}
 
-	// Parse week number from query params
-	if weekStr := c.Query("week"); weekStr != "" {
-		if w, err := strconv.Atoi(weekStr); err == nil && w >= 1 && w <= 5 {
-			weekNumber = w
+	// The week is derived from the snapshot date: an explicit date, the start of a
+	// requested week (backfill), or today clamped into the requested month
+	snapshotDate := now
+	if dateStr := c.Query("date"); dateStr != "" {
+		d, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+		if err != nil || int(d.Month()) != month || d.Year() != year {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid date, expected YYYY-MM-DD within the selected month",
+			})
+			return
+		}
+		snapshotDate = d.Add(12 * time.Hour)
+	} else if weekStr := c.Query("week"); weekStr != "" {
+		w, err := strconv.Atoi(weekStr)
+		d, ok := services.DateForWeekOfMonth(month, year, w, config.AppConfig.WeekStartDay)
+		if err != nil || !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid week value for the selected month",
+			})
+			return
+		}
+		// Keep today's date when it already falls in the requested week
+		if services.NewSnapshotPeriod(month, year, now).WeekNumber != w {
+			snapshotDate = d
 		}
 	}
+	period := services.NewSnapshotPeriod(month, year, snapshotDate)
 
-	log.Printf("Saving snapshot for user %s, month=%d, year=%d, week=%d", user.Email, month, year, weekNumber)
+	log.Printf("Saving snapshot for user %s, month=%d, year=%d, week=%d (ISO %d-W%02d)",
+		user.Email, month, year, period.WeekNumber, period.ISOYear, period.ISOWeek)
 
 	// Get current dashboard data
 	dashboardData, err := h.dashboardService.GetDashboardData(c.Request.Context(), user, month, year)
@@ -281,9 +310,31 @@ func (h *DashboardHandler) SaveSnapshot(c *gin.Context) {
 		})
 		return
 	}
+	// An empty dashboard (e.g. a failed sheet fetch) must not replace the week's current version
+	if len(dashboardData.Indicators) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error":   "No indicator data available for this month, nothing to save",
+		})
+		return
+	}
+
+	// Summarise the version being superseded, for the audit log
+	before, err := h.dashboardService.GetSnapshotSummary(month, year, period.WeekNumber)
+	if err != nil {
+		log.Printf("Warning: Failed to summarise existing snapshot: %v", err)
+	}
 
-	// Save snapshot (will delete existing data for same week first)
-	if err := h.dashboardService.SaveSnapshot(dashboardData.Indicators, month, year, weekNumber); err != nil {
+	// Save as a new version of the week; earlier versions are kept
+	version, err := h.dashboardService.SaveSnapshot(dashboardData.Indicators, period, user.Email)
+	if errors.Is(err, services.ErrSnapshotConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "This week was saved by someone else at the same time, please retry",
+		})
+		return
+	}
+	if err != nil {
 		log.Printf("Failed to save snapshot: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -292,14 +343,23 @@ func (h *DashboardHandler) SaveSnapshot(c *gin.Context) {
 		return
 	}
 
+	after, _ := h.dashboardService.GetSnapshotSummary(month, year, period.WeekNumber)
+	recordAudit(c, models.AuditSnapshotSave, "snapshot", weekTarget(month, year, period.WeekNumber), before, after)
+
+	h.digestService.NotifySnapshot(dashboardData, period.WeekNumber)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Snapshot saved successfully",
 		"data": gin.H{
-			"month":       month,
-			"year":        year,
-			"week_number": weekNumber,
-			"saved_at":    now.Format(time.RFC3339),
+			"month":         month,
+			"year":          year,
+			"week_number":   period.WeekNumber,
+			"version":       version,
+			"iso_year":      period.ISOYear,
+			"iso_week":      period.ISOWeek,
+			"snapshot_date": period.Date.Format("2006-01-02"),
+			"saved_at":      now.Format(time.RFC3339),
 		},
 	})
 }
@@ -326,16 +386,6 @@ func (h *DashboardHandler) GetSnapshotsByMonth(c *gin.Context) {
 		return
 	}
 
-	// Test spreadsheet access first
-	if err := h.sheetsService.TestConnection(c.Request.Context(), user); err != nil {
-		log.Printf("User %s does not have access to spreadsheet: %v", user.Email, err)
-		c.JSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error":   "You do not have access to the performance spreadsheet. Please contact your administrator.",
-		})
-		return
-	}
-
 	now := time.Now()
 	month := int(now.Month())
 	year := now.Year()
@@ -352,6 +402,11 @@ func (h *DashboardHandler) GetSnapshotsByMonth(c *gin.Context) {
 		}
 	}
 
+	// Test spreadsheet access first
+	if !checkSourceAccess(c, h.kpiSource, user, year) {
+		return
+	}
+
 	data, err := h.dashboardService.GetSnapshotsByMonth(month, year)
 	if err != nil {
 		log.Printf("Failed to get monthly snapshots: %v", err)
@@ -376,7 +431,7 @@ func (h *DashboardHandler) GetSnapshotsByMonth(c *gin.Context) {
 // @Security BearerAuth
 // @Param month query int true "Month (1-12)"
 // @Param year query int true "Year"
-// @Param week query int true "Week number (1-5)"
+// @Param week query int true "Week number (1-6)"
 // @Success 200 {object} map[string]interface{} "Snapshot deleted"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
@@ -392,6 +447,41 @@ func (h *DashboardHandler) DeleteSnapshot(c *gin.Context) {
 		return
 	}
 
+	month, year, week, ok := parseWeekQuery(c)
+	if !ok {
+		return
+	}
+
+	log.Printf("Deleting snapshot for month=%d, year=%d, week=%d", month, year, week)
+
+	snapshot, err := h.dashboardService.GetSnapshotSummary(month, year, week)
+	if err != nil {
+		log.Printf("Warning: Failed to summarise snapshot before delete: %v", err)
+	}
+	var screenshots int64
+	database.DB.Model(&models.Screenshot{}).Where("month = ? AND year = ? AND week = ?", month, year, week).Count(&screenshots)
+	before := gin.H{"snapshot": snapshot, "screenshot": screenshots > 0}
+
+	if err := h.dashboardService.DeleteSnapshotWeek(month, year, week); err != nil {
+		log.Printf("Failed to delete snapshot: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete snapshot",
+		})
+		return
+	}
+
+	recordAudit(c, models.AuditSnapshotDelete, "snapshot", weekTarget(month, year, week), before, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Snapshot deleted successfully",
+	})
+}
+
+// parseWeekQuery reads the required month, year and week query parameters,
+// writing a bad request response if any is missing or invalid
+func parseWeekQuery(c *gin.Context) (int, int, int, bool) {
 	monthStr := c.Query("month")
 	yearStr := c.Query("year")
 	weekStr := c.Query("week")
@@ -401,7 +491,7 @@ func (h *DashboardHandler) DeleteSnapshot(c *gin.Context) {
 			"success": false,
 			"error":   "Month, year, and week are required",
 		})
-		return
+		return 0, 0, 0, false
 	}
 
 	month, err := strconv.Atoi(monthStr)
@@ -410,7 +500,7 @@ func (h *DashboardHandler) DeleteSnapshot(c *gin.Context) {
 			"success": false,
 			"error":   "Invalid month value",
 		})
-		return
+		return 0, 0, 0, false
 	}
 
 	year, err := strconv.Atoi(yearStr)
@@ -419,33 +509,35 @@ func (h *DashboardHandler) DeleteSnapshot(c *gin.Context) {
 			"success": false,
 			"error":   "Invalid year value",
 		})
-		return
+		return 0, 0, 0, false
 	}
 
 	week, err := strconv.Atoi(weekStr)
-	if err != nil || week < 1 || week > 5 {
+	if err != nil || week < 1 || week > services.MaxWeeksInMonth {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   "Invalid week value",
 		})
-		return
+		return 0, 0, 0, false
 	}
 
-	log.Printf("Deleting snapshot for month=%d, year=%d, week=%d", month, year, week)
+	return month, year, week, true
+}
 
-	if err := h.dashboardService.DeleteSnapshotWeek(month, year, week); err != nil {
-		log.Printf("Failed to delete snapshot: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+// checkSourceAccess verifies that the user can read the year's KPI data, writing a forbidden
+// response if not. Requests made with a personal API token are checked with the token owner's
+// Google credentials, so a token never reads more than its owner could; successes are
+// remembered by the KPI cache for its TTL.
+func checkSourceAccess(c *gin.Context, source services.KPISource, user *models.User, year int) bool {
+	if err := source.TestConnection(c.Request.Context(), user, year); err != nil {
+		log.Printf("User %s does not have access to spreadsheet: %v", user.Email, err)
+		c.JSON(http.StatusForbidden, gin.H{
 			"success": false,
-			"error":   "Failed to delete snapshot",
+			"error":   "You do not have access to the performance spreadsheet. Please contact your administrator.",
 		})
-		return
+		return false
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Snapshot deleted successfully",
-	})
+	return true
 }
 
 // HealthCheck returns API health status
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// stubSource is a KPISource whose access check fails for the listed users
+type stubSource struct {
+	denied  map[uint]bool
+	checked []uint // Users whose access was checked, in order
+}
+
+func (s *stubSource) FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]services.KPIData, error) {
+	return nil, nil
+}
+
+func (s *stubSource) GetLayout(ctx context.Context, user *models.User, year int) (*services.DiscoveredLayout, error) {
+	return &services.DiscoveredLayout{}, nil
+}
+
+func (s *stubSource) TestConnection(ctx context.Context, user *models.User, year int) error {
+	s.checked = append(s.checked, user.ID)
+	if s.denied[user.ID] {
+		return errors.New("no access to spreadsheet")
+	}
+	return nil
+}
+
+func (s *stubSource) Years() []int { return []int{2026} }
+
+func (s *stubSource) InvalidateLayout() {}
+
+// testUser returns a viewer with the given ID
+func testUser(id uint, email string) *models.User {
+	user := &models.User{Email: email, Role: models.RoleViewer}
+	user.ID = id
+	return user
+}
+
+// newTestContext returns a context for a request by user, authenticated with token if not nil
+func newTestContext(method, body string, user *models.User, token *models.APIToken) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user", *user)
+	c.Set("userID", user.ID)
+	c.Set("email", user.Email)
+	if token != nil {
+		c.Set("apiToken", token)
+	}
+	return c, w
+}
+
+func TestCheckSourceAccess(t *testing.T) {
+	owner := testUser(1, "owner@corp.com")
+	outsider := testUser(2, "outsider@gmail.com")
+	readToken := &models.APIToken{ID: 9, UserID: 2, Scope: models.ScopeRead, ExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		token      *models.APIToken
+		wantOK     bool
+		wantStatus int
+	}{
+		{"session with access", owner, nil, true, http.StatusOK},
+		{"session without access", outsider, nil, false, http.StatusForbidden},
+		{"API token of a user with access", owner, &models.APIToken{ID: 8, UserID: 1, Scope: models.ScopeRead}, true, http.StatusOK},
+		{"API token of a user without access", outsider, readToken, false, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &stubSource{denied: map[uint]bool{outsider.ID: true}}
+			c, w := newTestContext(http.MethodGet, "", tt.user, tt.token)
+
+			if ok := checkSourceAccess(c, source, tt.user, 2026); ok != tt.wantOK {
+				t.Errorf("checkSourceAccess() = %v, want %v", ok, tt.wantOK)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			// Token requests are checked with the owner's own access, like sessions
+			if len(source.checked) != 1 || source.checked[0] != tt.user.ID {
+				t.Errorf("access checked for users %v, want [%d]", source.checked, tt.user.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DigestHandler handles the weekly digest email endpoints
+type DigestHandler struct {
+	digestService    *services.DigestService
+	dashboardService *services.DashboardService
+	kpiSource        services.KPISource
+}
+
+// NewDigestHandler creates a new DigestHandler instance
+func NewDigestHandler(digestService *services.DigestService, dashboardService *services.DashboardService, kpiSource services.KPISource) *DigestHandler {
+	return &DigestHandler{
+		digestService:    digestService,
+		dashboardService: dashboardService,
+		kpiSource:        kpiSource,
+	}
+}
+
+// DigestSettings represents the mail configuration and digest recipients
+type DigestSettings struct {
+	SMTPConfigured bool                     `json:"smtp_configured"`
+	SMTPHost       string                   `json:"smtp_host"`
+	SMTPPort       int                      `json:"smtp_port"`
+	SMTPTLS        string                   `json:"smtp_tls"`
+	From           string                   `json:"from"`
+	OnSnapshot     bool                     `json:"on_snapshot"` // Sent after each snapshot save
+	Schedule       string                   `json:"schedule"`    // Cron expression, empty = disabled
+	Recipients     []models.DigestRecipient `json:"recipients"`
+}
+
+// DigestRecipientRequest represents the request to add a digest recipient
+type DigestRecipientRequest struct {
+	Email      string `json:"email"`
+	Department string `json:"department"` // Empty = whole dashboard
+}
+
+// GetDigestSettings returns the mail configuration and digest recipients
+// @Summary Get digest settings
+// @Description Returns the SMTP configuration (without credentials), when digests are sent and who receives them
+// @Tags digest
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Digest settings"
+// @Router /api/v1/digest [get]
+func (h *DigestHandler) GetDigestSettings(c *gin.Context) {
+	recipients, err := services.ListDigestRecipients()
+	if err != nil {
+		log.Printf("Failed to list digest recipients: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch digest recipients",
+		})
+		return
+	}
+
+	cfg := config.AppConfig
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": DigestSettings{
+			SMTPConfigured: services.MailConfigured(),
+			SMTPHost:       cfg.SMTPHost,
+			SMTPPort:       cfg.SMTPPort,
+			SMTPTLS:        cfg.SMTPTLS,
+			From:           cfg.SMTPFrom,
+			OnSnapshot:     cfg.DigestOnSnapshot,
+			Schedule:       cfg.DigestSchedule,
+			Recipients:     recipients,
+		},
+	})
+}
+
+// AddDigestRecipient adds a digest recipient, optionally limited to one department
+// @Summary Add digest recipient
+// @Description Adds an email address to the digest. With a department it only receives that department's indicators.
+// @Tags digest
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DigestRecipientRequest true "Email and optional department"
+// @Success 201 {object} map[string]interface{} "Recipient added"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 409 {object} map[string]interface{} "Already a recipient"
+// @Router /api/v1/digest/recipients [post]
+func (h *DigestHandler) AddDigestRecipient(c *gin.Context) {
+	var req DigestRecipientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil || len(addr.Address) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid email address",
+		})
+		return
+	}
+
+	department := strings.ToUpper(strings.TrimSpace(req.Department))
+	if department != "" {
+		var count int64
+		database.DB.Model(&models.Indicator{}).Where("department = ?", department).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Unknown department: " + department,
+			})
+			return
+		}
+	}
+
+	recipient := models.DigestRecipient{
+		Email:      strings.ToLower(addr.Address),
+		Department: department,
+	}
+	if err := database.DB.Create(&recipient).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"error":   "This address already receives the digest for that department",
+			})
+			return
+		}
+		log.Printf("Failed to add digest recipient: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to add digest recipient",
+		})
+		return
+	}
+
+	recordAudit(c, models.AuditDigestRecipientAdd, "digest_recipient", strconv.Itoa(int(recipient.ID)), nil, recipient)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Digest recipient added",
+		"data":    recipient,
+	})
+}
+
+// DeleteDigestRecipient removes a digest recipient
+// @Summary Delete digest recipient
+// @Tags digest
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Recipient ID"
+// @Success 200 {object} map[string]interface{} "Recipient removed"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/digest/recipients/{id} [delete]
+func (h *DigestHandler) DeleteDigestRecipient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid recipient ID",
+		})
+		return
+	}
+
+	var recipient models.DigestRecipient
+	if err := database.DB.First(&recipient, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Digest recipient not found",
+		})
+		return
+	}
+
+	if err := database.DB.Delete(&recipient).Error; err != nil {
+		log.Printf("Failed to delete digest recipient %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete digest recipient",
+		})
+		return
+	}
+
+	recordAudit(c, models.AuditDigestRecipientDelete, "digest_recipient", strconv.FormatUint(id, 10), recipient, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Digest recipient removed",
+	})
+}
+
+// PreviewDigest renders the digest email as HTML
+// @Summary Preview digest
+// @Description Renders the digest email of a month and week from the current dashboard data
+// @Tags digest
+// @Produce text/html
+// @Security BearerAuth
+// @Param month query int false "Month (1-12)" default(current month)
+// @Param year query int false "Year" default(current year)
+// @Param week query int false "Week number (1-6)" default(current week)
+// @Param department query string false "Limit to one department"
+// @Success 200 {string} string "Digest HTML"
+// @Router /api/v1/digest/preview [get]
+func (h *DigestHandler) PreviewDigest(c *gin.Context) {
+	data, week, ok := h.digestData(c)
+	if !ok {
+		return
+	}
+
+	department := strings.ToUpper(strings.TrimSpace(c.Query("department")))
+	html, err := services.RenderDigest(services.BuildDigest(data, week, department))
+	if err != nil {
+		log.Printf("Failed to render digest: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to render digest",
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+}
+
+// SendDigest sends the digest of a month and week to all recipients now
+// @Summary Send digest now
+// @Description Emails the digest of a month and week to every recipient, e.g. to test the SMTP settings
+// @Tags digest
+// @Produce json
+// @Security BearerAuth
+// @Param month query int false "Month (1-12)" default(current month)
+// @Param year query int false "Year" default(current year)
+// @Param week query int false "Week number (1-6)" default(current week)
+// @Success 200 {object} map[string]interface{} "Emails sent"
+// @Failure 400 {object} map[string]interface{} "SMTP not configured"
+// @Failure 502 {object} map[string]interface{} "Every email failed"
+// @Router /api/v1/digest/send [post]
+func (h *DigestHandler) SendDigest(c *gin.Context) {
+	if !services.MailConfigured() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No SMTP server configured (SMTP_HOST)",
+		})
+		return
+	}
+
+	data, week, ok := h.digestData(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.digestService.Send(data, week)
+	if err != nil {
+		log.Printf("Failed to send digest: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to send digest",
+		})
+		return
+	}
+
+	recordAudit(c, models.AuditDigestSend, "digest", weekTarget(data.Period.Month, data.Period.Year, week), nil, result)
+
+	if result.Emails == 0 && len(result.Errors) > 0 {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"success": false,
+			"error":   "Failed to send digest: " + result.Errors[0],
+			"data":    result,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Digest sent",
+		"data":    result,
+	})
+}
+
+// digestData fetches the dashboard for the month, year and week query parameters as the current user,
+// writing an error response on failure
+func (h *DigestHandler) digestData(c *gin.Context) (*services.DashboardResponse, int, bool) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return nil, 0, false
+	}
+
+	month, year := monthYearQuery(c)
+	week := services.NewSnapshotPeriod(month, year, time.Now()).WeekNumber
+	if weekStr := c.Query("week"); weekStr != "" {
+		w, err := strconv.Atoi(weekStr)
+		if err != nil || w < 1 || w > services.MaxWeeksInMonth {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid week value",
+			})
+			return nil, 0, false
+		}
+		week = w
+	}
+
+	if !checkSourceAccess(c, h.kpiSource, user, year) {
+		return nil, 0, false
+	}
+
+	data, err := h.dashboardService.GetDashboardData(c.Request.Context(), user, month, year)
+	if err != nil {
+		log.Printf("Failed to get dashboard data for digest: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch dashboard data. Please try again later.",
+		})
+		return nil, 0, false
+	}
+
+	return data, week, true
+}
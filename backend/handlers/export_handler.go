@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportDashboard downloads the dashboard indicators as a CSV or XLSX file
+// @Summary Export dashboard
+// @Description Downloads the month's dashboard indicators (target, performance, percentage, status, WoW,
+// @Description expected progress, variance and schedule status) as a CSV or XLSX file
+// @Tags dashboard
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param month query int false "Month (1-12)" default(current month)
+// @Param year query int false "Year" default(current year)
+// @Param format query string false "csv or xlsx" default(csv)
+// @Success 200 {file} file "Exported dashboard"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "No access to the spreadsheet"
+// @Router /api/v1/dashboard/export [get]
+func (h *DashboardHandler) ExportDashboard(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	format, ok := exportFormatQuery(c)
+	if !ok {
+		return
+	}
+	month, year := monthYearQuery(c)
+
+	if !checkSourceAccess(c, h.kpiSource, user, year) {
+		return
+	}
+
+	dashboardData, err := h.dashboardService.GetDashboardData(c.Request.Context(), user, month, year)
+	if err != nil {
+		log.Printf("Failed to get dashboard data for export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch dashboard data. Please try again later.",
+		})
+		return
+	}
+
+	data, err := services.ExportDashboard(dashboardData, format)
+	if err != nil {
+		log.Printf("Failed to export dashboard: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to export dashboard",
+		})
+		return
+	}
+
+	sendExport(c, fmt.Sprintf("dashboard-%d-%02d.%s", year, month, format), format, data)
+}
+
+// ExportSnapshots downloads the month's week-by-indicator snapshot matrix as a CSV or XLSX file
+// @Summary Export monthly snapshots
+// @Description Downloads the saved weekly percentages of every indicator for a month as a CSV or XLSX file
+// @Tags dashboard
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param month query int false "Month (1-12)" default(current month)
+// @Param year query int false "Year" default(current year)
+// @Param format query string false "csv or xlsx" default(csv)
+// @Success 200 {file} file "Exported snapshots"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "No access to the spreadsheet"
+// @Router /api/v1/dashboard/snapshots/export [get]
+func (h *DashboardHandler) ExportSnapshots(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	format, ok := exportFormatQuery(c)
+	if !ok {
+		return
+	}
+	month, year := monthYearQuery(c)
+
+	if !checkSourceAccess(c, h.kpiSource, user, year) {
+		return
+	}
+
+	snapshots, err := h.dashboardService.GetSnapshotsByMonth(month, year)
+	if err != nil {
+		log.Printf("Failed to get monthly snapshots for export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch snapshot data",
+		})
+		return
+	}
+
+	data, err := services.ExportMonthlySnapshots(snapshots, format)
+	if err != nil {
+		log.Printf("Failed to export snapshots: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to export snapshots",
+		})
+		return
+	}
+
+	sendExport(c, fmt.Sprintf("snapshots-%d-%02d.%s", year, month, format), format, data)
+}
+
+// exportFormatQuery reads the export format (csv by default), writing a bad request response if unsupported
+func exportFormatQuery(c *gin.Context) (string, bool) {
+	format := strings.ToLower(c.DefaultQuery("format", services.ExportFormatCSV))
+	if !services.IsValidExportFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Format must be csv or xlsx",
+		})
+		return "", false
+	}
+	return format, true
+}
+
+// monthYearQuery reads the month and year query parameters, defaulting to the current month
+func monthYearQuery(c *gin.Context) (int, int) {
+	now := time.Now()
+	month := int(now.Month())
+	year := now.Year()
+
+	if monthStr := c.Query("month"); monthStr != "" {
+		if m, err := strconv.Atoi(monthStr); err == nil && m >= 1 && m <= 12 {
+			month = m
+		}
+	}
+
+	if yearStr := c.Query("year"); yearStr != "" {
+		if y, err := strconv.Atoi(yearStr); err == nil && y >= 2020 && y <= 2100 {
+			year = y
+		}
+	}
+
+	return month, year
+}
+
+// sendExport writes an exported file as an attachment
+func sendExport(c *gin.Context, filename, format string, data []byte) {
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, services.ExportContentType(format), data)
+}
@@ -0,0 +1,476 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IndicatorHandler handles indicator master data endpoints
+type IndicatorHandler struct{}
+
+// NewIndicatorHandler creates a new IndicatorHandler instance
+func NewIndicatorHandler() *IndicatorHandler {
+	return &IndicatorHandler{}
+}
+
+// IndicatorRequest represents the request to create or update an indicator.
+// Fields are pointers so updates only touch what the client sends.
+type IndicatorRequest struct {
+	Code            *string  `json:"code"`
+	Department      *string  `json:"department"`
+	Name            *string  `json:"name"`
+	UnitOfMeasure   *string  `json:"unit_of_measure"`
+	SpreadsheetName *string  `json:"spreadsheet_name"`
+	SpreadsheetRow  *int     `json:"spreadsheet_row"`
+	IsInverse       *bool    `json:"is_inverse"`
+	DisplayOrder    *int     `json:"display_order"`
+	IsActive        *bool    `json:"is_active"`
+	Weight          *float64 `json:"weight"`
+}
+
+// ReorderIndicatorsRequest represents the request to reorder indicators
+type ReorderIndicatorsRequest struct {
+	Codes []string `json:"codes"` // Indicator codes in the desired display order
+}
+
+// apply copies the provided fields onto the indicator
+func (r *IndicatorRequest) apply(indicator *models.Indicator) {
+	if r.Code != nil {
+		indicator.Code = strings.TrimSpace(*r.Code)
+	}
+	if r.Department != nil {
+		indicator.Department = strings.TrimSpace(*r.Department)
+	}
+	if r.Name != nil {
+		indicator.Name = strings.TrimSpace(*r.Name)
+	}
+	if r.UnitOfMeasure != nil {
+		indicator.UnitOfMeasure = strings.TrimSpace(*r.UnitOfMeasure)
+	}
+	if r.SpreadsheetName != nil {
+		indicator.SpreadsheetName = strings.TrimSpace(*r.SpreadsheetName)
+	}
+	if r.SpreadsheetRow != nil {
+		indicator.SpreadsheetRow = *r.SpreadsheetRow
+	}
+	if r.IsInverse != nil {
+		indicator.IsInverse = *r.IsInverse
+	}
+	if r.DisplayOrder != nil {
+		indicator.DisplayOrder = *r.DisplayOrder
+	}
+	if r.IsActive != nil {
+		indicator.IsActive = *r.IsActive
+	}
+	if r.Weight != nil {
+		indicator.Weight = *r.Weight
+	}
+}
+
+// validateIndicator checks the required indicator fields
+func validateIndicator(indicator *models.Indicator) string {
+	switch {
+	case indicator.Code == "":
+		return "Code is required"
+	case indicator.Department == "":
+		return "Department is required"
+	case indicator.Name == "":
+		return "Name is required"
+	case indicator.SpreadsheetRow < 0:
+		return "Spreadsheet row must not be negative"
+	case indicator.SpreadsheetName == "" && indicator.SpreadsheetRow == 0:
+		return "Spreadsheet name or spreadsheet row is required"
+	case indicator.Weight < 0:
+		return "Weight must not be negative"
+	}
+	return ""
+}
+
+// ListIndicators returns all indicators ordered by display order
+// @Summary List indicators
+// @Description Returns all KPI indicators, optionally only active ones
+// @Tags indicators
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Only return active indicators"
+// @Success 200 {object} map[string]interface{} "Indicators"
+// @Router /api/v1/indicators [get]
+func (h *IndicatorHandler) ListIndicators(c *gin.Context) {
+	query := database.DB.Order("display_order, id")
+	if c.Query("active") == "true" {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var indicators []models.Indicator
+	if err := query.Find(&indicators).Error; err != nil {
+		log.Printf("Failed to list indicators: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch indicators",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    indicators,
+	})
+}
+
+// GetIndicator returns a single indicator
+func (h *IndicatorHandler) GetIndicator(c *gin.Context) {
+	indicator, ok := findIndicator(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    indicator,
+	})
+}
+
+// CreateIndicator creates a new indicator
+// @Summary Create indicator
+// @Description Creates a new KPI indicator. A previously deleted indicator with the same code is restored.
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} map[string]interface{} "Indicator created"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 409 {object} map[string]interface{} "Code already exists"
+// @Router /api/v1/indicators [post]
+func (h *IndicatorHandler) CreateIndicator(c *gin.Context) {
+	var req IndicatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	indicator := models.Indicator{IsActive: true, Weight: 1}
+	req.apply(&indicator)
+
+	if indicator.DisplayOrder == 0 {
+		var maxOrder int
+		database.DB.Model(&models.Indicator{}).Select("COALESCE(MAX(display_order), 0)").Scan(&maxOrder)
+		indicator.DisplayOrder = maxOrder + 1
+	}
+
+	if msg := validateIndicator(&indicator); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   msg,
+		})
+		return
+	}
+
+	// Codes are unique across soft-deleted rows too, so a deleted code is restored instead
+	var existing models.Indicator
+	result := database.DB.Unscoped().Where("code = ?", indicator.Code).First(&existing)
+	if result.Error == nil {
+		if !existing.DeletedAt.Valid {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"error":   "An indicator with this code already exists",
+			})
+			return
+		}
+
+		indicator.ID = existing.ID
+		indicator.CreatedAt = existing.CreatedAt
+		if err := database.DB.Unscoped().Save(&indicator).Error; err != nil {
+			log.Printf("Failed to restore indicator %s: %v", indicator.Code, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to create indicator",
+			})
+			return
+		}
+		log.Printf("Restored deleted indicator: %s - %s", indicator.Code, indicator.Name)
+	} else {
+		if err := database.DB.Create(&indicator).Error; err != nil {
+			log.Printf("Failed to create indicator %s: %v", indicator.Code, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to create indicator",
+			})
+			return
+		}
+		// GORM applies the column defaults to zero values on insert
+		if !indicator.IsActive {
+			database.DB.Model(&indicator).Update("is_active", false)
+		}
+		if indicator.Weight == 0 {
+			database.DB.Model(&indicator).Update("weight", 0)
+		}
+		log.Printf("Created indicator: %s - %s", indicator.Code, indicator.Name)
+	}
+	recordAudit(c, models.AuditIndicatorCreate, "indicator", indicator.Code, nil, indicator)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Indicator created successfully",
+		"data":    indicator,
+	})
+}
+
+// UpdateIndicator updates the provided fields of an indicator
+// @Summary Update indicator
+// @Description Updates code, department, name, unit, spreadsheet mapping, inverse flag, order or active state.
+// @Description A new code is carried over to the indicator's snapshots, threshold override and alert rules.
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Indicator ID"
+// @Success 200 {object} map[string]interface{} "Indicator updated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Indicator not found"
+// @Failure 409 {object} map[string]interface{} "Code already exists"
+// @Router /api/v1/indicators/{id} [put]
+func (h *IndicatorHandler) UpdateIndicator(c *gin.Context) {
+	indicator, ok := findIndicator(c)
+	if !ok {
+		return
+	}
+
+	var req IndicatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	before := *indicator
+	previousCode := indicator.Code
+	req.apply(indicator)
+
+	if msg := validateIndicator(indicator); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   msg,
+		})
+		return
+	}
+
+	if indicator.Code != previousCode {
+		var count int64
+		database.DB.Unscoped().Model(&models.Indicator{}).
+			Where("code = ? AND id <> ?", indicator.Code, indicator.ID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"error":   "An indicator with this code already exists",
+			})
+			return
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(indicator).Error; err != nil {
+			return err
+		}
+		if indicator.Code != previousCode {
+			return renameIndicatorCode(tx, previousCode, indicator.Code)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to update indicator %s: %v", indicator.Code, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to update indicator",
+		})
+		return
+	}
+	log.Printf("Updated indicator: %s - %s", indicator.Code, indicator.Name)
+	recordAudit(c, models.AuditIndicatorUpdate, "indicator", previousCode, before, indicator)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Indicator updated successfully",
+		"data":    indicator,
+	})
+}
+
+// renameIndicatorCode moves the rows that reference an indicator by code to its new code,
+// so a rename keeps the snapshot history, threshold override and alert rules
+func renameIndicatorCode(tx *gorm.DB, from, to string) error {
+	updates := []struct {
+		model  interface{}
+		column string
+	}{
+		{&models.WeeklySnapshot{}, "indicator_id"},
+		{&models.StatusThreshold{}, "indicator_code"},
+		{&models.AlertRule{}, "indicator_code"},
+		{&models.Alert{}, "indicator_code"},
+	}
+	for _, u := range updates {
+		if err := tx.Model(u.model).Where(u.column+" = ?", from).Update(u.column, to).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActivateIndicator re-enables an indicator on the dashboard
+func (h *IndicatorHandler) ActivateIndicator(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+// DeactivateIndicator hides an indicator from the dashboard without deleting it
+func (h *IndicatorHandler) DeactivateIndicator(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// setActive updates the active flag of an indicator
+func (h *IndicatorHandler) setActive(c *gin.Context, active bool) {
+	indicator, ok := findIndicator(c)
+	if !ok {
+		return
+	}
+
+	before := *indicator
+	if err := database.DB.Model(indicator).Update("is_active", active).Error; err != nil {
+		log.Printf("Failed to update indicator %s: %v", indicator.Code, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to update indicator",
+		})
+		return
+	}
+	log.Printf("Indicator %s is_active=%t", indicator.Code, active)
+	recordAudit(c, models.AuditIndicatorUpdate, "indicator", indicator.Code, before, indicator)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Indicator updated successfully",
+		"data":    indicator,
+	})
+}
+
+// ReorderIndicators sets display order from an ordered list of indicator codes
+// @Summary Reorder indicators
+// @Description Assigns display_order 1..n following the given list of codes
+// @Tags indicators
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Indicators reordered"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/indicators/reorder [put]
+func (h *IndicatorHandler) ReorderIndicators(c *gin.Context) {
+	var req ReorderIndicatorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Codes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "A list of indicator codes is required",
+		})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for i, code := range req.Codes {
+			result := tx.Model(&models.Indicator{}).Where("code = ?", code).Update("display_order", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errUnknownIndicator{code: code}
+			}
+		}
+		return nil
+	})
+
+	var unknown errUnknownIndicator
+	if errors.As(err, &unknown) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Unknown indicator code: " + unknown.code,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to reorder indicators: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to reorder indicators",
+		})
+		return
+	}
+
+	log.Printf("Reordered %d indicators", len(req.Codes))
+	recordAudit(c, models.AuditIndicatorReorder, "indicator", "", nil, req)
+	h.ListIndicators(c)
+}
+
+// DeleteIndicator deletes an indicator. Its historical snapshots are kept.
+func (h *IndicatorHandler) DeleteIndicator(c *gin.Context) {
+	indicator, ok := findIndicator(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Delete(indicator).Error; err != nil {
+		log.Printf("Failed to delete indicator %s: %v", indicator.Code, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete indicator",
+		})
+		return
+	}
+	log.Printf("Deleted indicator: %s - %s", indicator.Code, indicator.Name)
+	recordAudit(c, models.AuditIndicatorDelete, "indicator", indicator.Code, indicator, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Indicator deleted successfully",
+	})
+}
+
+// errUnknownIndicator is returned when a reorder request references a missing code
+type errUnknownIndicator struct {
+	code string
+}
+
+func (e errUnknownIndicator) Error() string {
+	return "unknown indicator code: " + e.code
+}
+
+// findIndicator loads the indicator from the :id path parameter, writing an error response if missing
+func findIndicator(c *gin.Context) (*models.Indicator, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid indicator ID",
+		})
+		return nil, false
+	}
+
+	var indicator models.Indicator
+	if err := database.DB.First(&indicator, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Indicator not found",
+		})
+		return nil, false
+	}
+
+	return &indicator, true
+}
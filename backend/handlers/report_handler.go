@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListReports returns the generated PDF reports of a month
+// @Summary List weekly reports
+// @Description Returns the server-generated PDF reports of a month (without their content)
+// @Tags dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param month query int false "Month (1-12)" default(current month)
+// @Param year query int false "Year" default(current year)
+// @Success 200 {object} map[string]interface{} "Weekly reports"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /api/v1/dashboard/reports [get]
+func (h *DashboardHandler) ListReports(c *gin.Context) {
+	month, year := monthYearQuery(c)
+
+	reports, err := h.dashboardService.ListWeeklyReports(month, year)
+	if err != nil {
+		log.Printf("Failed to list weekly reports: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch reports",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reports,
+	})
+}
+
+// DownloadReport downloads a week's PDF report
+// @Summary Download weekly report
+// @Description Downloads the PDF report generated for a week
+// @Tags dashboard
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param month query int true "Month (1-12)"
+// @Param year query int true "Year"
+// @Param week query int true "Week number (1-6)"
+// @Success 200 {file} file "PDF report"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "No report for this week"
+// @Router /api/v1/dashboard/report [get]
+func (h *DashboardHandler) DownloadReport(c *gin.Context) {
+	month, year, week, ok := parseWeekQuery(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.dashboardService.GetWeeklyReport(month, year, week)
+	if errors.Is(err, services.ErrWeeklyReportNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "No report has been generated for this week",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to load weekly report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch report",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\""+report.Filename+"\"")
+	c.Data(http.StatusOK, "application/pdf", report.PDFData)
+}
+
+// GenerateReport renders a week's PDF report from its saved snapshot
+// @Summary Generate weekly report
+// @Description Generates (or regenerates) the PDF report of a week from the week's current snapshot.
+// @Description Reports are also generated whenever the week's snapshot is saved.
+// @Tags dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param month query int true "Month (1-12)"
+// @Param year query int true "Year"
+// @Param week query int true "Week number (1-6)"
+// @Success 200 {object} map[string]interface{} "Report generated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "No snapshot saved for the week"
+// @Router /api/v1/dashboard/report [post]
+func (h *DashboardHandler) GenerateReport(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	month, year, week, ok := parseWeekQuery(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.dashboardService.GenerateWeeklyReport(month, year, week, user.Email)
+	if errors.Is(err, services.ErrNoSnapshotForWeek) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Save a snapshot of this week before generating its report",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to generate weekly report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to generate report",
+		})
+		return
+	}
+
+	recordAudit(c, models.AuditReportGenerate, "report", weekTarget(month, year, week), nil, gin.H{
+		"id":               report.ID,
+		"filename":         report.Filename,
+		"size_bytes":       report.SizeBytes,
+		"snapshot_version": report.SnapshotVersion,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Report generated for week " + strconv.Itoa(week),
+		"data":    report,
+	})
+}
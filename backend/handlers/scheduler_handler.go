@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchedulerHandler handles automatic snapshot scheduling endpoints
+type SchedulerHandler struct {
+	scheduler *services.SnapshotScheduler
+}
+
+// NewSchedulerHandler creates a new SchedulerHandler instance
+func NewSchedulerHandler(scheduler *services.SnapshotScheduler) *SchedulerHandler {
+	return &SchedulerHandler{
+		scheduler: scheduler,
+	}
+}
+
+// SchedulerSettings represents the automatic snapshot configuration
+type SchedulerSettings struct {
+	Schedule    string     `json:"schedule"`     // Cron expression, empty = disabled
+	ServiceUser string     `json:"service_user"` // Email of the user whose token is used
+	NextRun     *time.Time `json:"next_run,omitempty"`
+}
+
+// GetSchedulerSettings returns the snapshot schedule, service user and next run time
+func (h *SchedulerHandler) GetSchedulerSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": SchedulerSettings{
+			Schedule:    config.AppConfig.SnapshotSchedule,
+			ServiceUser: config.AppConfig.SnapshotServiceUser,
+			NextRun:     h.scheduler.NextRun(),
+		},
+	})
+}
+
+// UpdateSchedulerSettings updates the snapshot schedule and service user
+// @Summary Update snapshot schedule
+// @Description Sets the cron expression (e.g. "0 17 * * FRI") and the service user for automatic snapshots
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Schedule updated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/scheduler [put]
+func (h *SchedulerHandler) UpdateSchedulerSettings(c *gin.Context) {
+	var req SchedulerSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	req.Schedule = strings.TrimSpace(req.Schedule)
+	req.ServiceUser = strings.TrimSpace(req.ServiceUser)
+
+	if err := services.ValidateSchedule(req.Schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if req.ServiceUser != "" {
+		var count int64
+		database.DB.Model(&models.User{}).Where("email = ?", req.ServiceUser).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Service user must have logged in at least once",
+			})
+			return
+		}
+	}
+
+	if err := upsertSetting(models.SettingSnapshotCron, req.Schedule); err != nil {
+		log.Printf("Failed to save snapshot_schedule setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
+	if err := upsertSetting(models.SettingSnapshotUser, req.ServiceUser); err != nil {
+		log.Printf("Failed to save snapshot_service_user setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
+	before := SchedulerSettings{
+		Schedule:    config.AppConfig.SnapshotSchedule,
+		ServiceUser: config.AppConfig.SnapshotServiceUser,
+	}
+	config.AppConfig.SnapshotSchedule = req.Schedule
+	config.AppConfig.SnapshotServiceUser = req.ServiceUser
+	recordAudit(c, models.AuditSchedulerUpdate, "setting", "scheduler", before, SchedulerSettings{
+		Schedule:    req.Schedule,
+		ServiceUser: req.ServiceUser,
+	})
+
+	if err := h.scheduler.Reschedule(req.Schedule); err != nil {
+		log.Printf("Failed to reschedule snapshots: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Settings saved but the schedule could not be applied",
+		})
+		return
+	}
+
+	h.GetSchedulerSettings(c)
+}
+
+// GetSnapshotRuns returns the history of automatic and manual snapshot runs
+// @Summary List snapshot runs
+// @Description Returns recent snapshot runs, newest first
+// @Tags scheduler
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Maximum number of runs" default(50)
+// @Param status query string false "Filter by status: running, success, failed"
+// @Success 200 {object} map[string]interface{} "Snapshot runs"
+// @Router /api/v1/scheduler/runs [get]
+func (h *SchedulerHandler) GetSnapshotRuns(c *gin.Context) {
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	query := database.DB.Order("started_at desc").Limit(limit)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var runs []models.SnapshotRun
+	if err := query.Find(&runs).Error; err != nil {
+		log.Printf("Failed to list snapshot runs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch snapshot runs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    runs,
+	})
+}
+
+// RunSnapshotNow takes an automatic snapshot immediately using the service user
+// @Summary Run snapshot now
+// @Description Takes a snapshot of the current month and week using the service user's token
+// @Tags scheduler
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Snapshot run"
+// @Failure 409 {object} map[string]interface{} "A run is already in progress"
+// @Failure 500 {object} map[string]interface{} "Snapshot failed or could not be started"
+// @Router /api/v1/scheduler/run [post]
+func (h *SchedulerHandler) RunSnapshotNow(c *gin.Context) {
+	triggeredBy := ""
+	if user, ok := middleware.GetCurrentUser(c); ok {
+		triggeredBy = user.Email
+	}
+
+	run, err := h.scheduler.RunNow(models.SnapshotRunManual, triggeredBy)
+	if run != nil {
+		recordAudit(c, models.AuditSchedulerRun, "snapshot_run", strconv.Itoa(int(run.ID)), nil, run)
+	}
+	if errors.Is(err, services.ErrSnapshotRunInProgress) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "A snapshot run is already in progress",
+		})
+		return
+	}
+	if run == nil {
+		log.Printf("Failed to start snapshot run: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to start snapshot run",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Snapshot run failed: " + err.Error(),
+			"data":    run,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Snapshot taken successfully",
+		"data":    run,
+	})
+}
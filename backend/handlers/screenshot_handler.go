@@ -11,16 +11,21 @@ import (
 	"weekly-dashboard/database"
 	"weekly-dashboard/middleware"
 	"weekly-dashboard/models"
+	"weekly-dashboard/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // ScreenshotHandler handles screenshot endpoints
-type ScreenshotHandler struct{}
+type ScreenshotHandler struct {
+	webhooks *services.WebhookService
+}
 
 // NewScreenshotHandler creates a new ScreenshotHandler instance
-func NewScreenshotHandler() *ScreenshotHandler {
-	return &ScreenshotHandler{}
+func NewScreenshotHandler(webhooks *services.WebhookService) *ScreenshotHandler {
+	return &ScreenshotHandler{
+		webhooks: webhooks,
+	}
 }
 
 // ScreenshotResponse represents the response for screenshot list
@@ -36,7 +41,7 @@ type ScreenshotResponse struct {
 
 // UploadScreenshot handles PNG screenshot upload and saves to database
 func (h *ScreenshotHandler) UploadScreenshot(c *gin.Context) {
-	_, ok := middleware.GetCurrentUser(c)
+	user, ok := middleware.GetCurrentUser(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
@@ -69,10 +74,10 @@ func (h *ScreenshotHandler) UploadScreenshot(c *gin.Context) {
 	}
 
 	week, err := strconv.Atoi(weekStr)
-	if err != nil || week < 1 || week > 5 {
+	if err != nil || week < 1 || week > services.MaxWeeksInMonth {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "Invalid week value (1-5)",
+			"error":   "Invalid week value (1-6)",
 		})
 		return
 	}
@@ -108,7 +113,15 @@ func (h *ScreenshotHandler) UploadScreenshot(c *gin.Context) {
 	result := database.DB.Where("month = ? AND year = ? AND week = ?", month, year, week).First(&existingScreenshot)
 
 	now := time.Now()
+	var before gin.H
+	var screenshotID uint
 	if result.Error == nil {
+		before = gin.H{
+			"filename":   existingScreenshot.Filename,
+			"size_bytes": existingScreenshot.SizeBytes,
+			"saved_at":   existingScreenshot.SavedAt,
+		}
+
 		// Update existing screenshot
 		existingScreenshot.ImageData = imageData
 		existingScreenshot.SizeBytes = int64(len(imageData))
@@ -123,6 +136,7 @@ func (h *ScreenshotHandler) UploadScreenshot(c *gin.Context) {
 			})
 			return
 		}
+		screenshotID = existingScreenshot.ID
 		log.Printf("Screenshot updated: %s (%d bytes)", filename, len(imageData))
 	} else {
 		// Create new screenshot
@@ -145,9 +159,25 @@ func (h *ScreenshotHandler) UploadScreenshot(c *gin.Context) {
 			})
 			return
 		}
+		screenshotID = screenshot.ID
 		log.Printf("Screenshot saved: %s (%d bytes)", filename, len(imageData))
 	}
 
+	recordAudit(c, models.AuditScreenshotUpload, "screenshot", weekTarget(month, year, week), before, gin.H{
+		"filename":   filename,
+		"size_bytes": len(imageData),
+		"saved_at":   now,
+	})
+	h.webhooks.Emit(models.WebhookEventScreenshotUploaded, services.ScreenshotUploadedEvent{
+		ID:         screenshotID,
+		Month:      month,
+		Year:       year,
+		Week:       week,
+		Filename:   filename,
+		SizeBytes:  int64(len(imageData)),
+		UploadedBy: user.Email,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Screenshot saved successfully",
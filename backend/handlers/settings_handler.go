@@ -9,6 +9,7 @@ import (
 	"weekly-dashboard/config"
 	"weekly-dashboard/database"
 	"weekly-dashboard/models"
+	"weekly-dashboard/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,13 +38,21 @@ type UpdateSpreadsheetRequest struct {
 
 // GetSpreadsheetSettings returns current spreadsheet configuration
 func (h *SettingsHandler) GetSpreadsheetSettings(c *gin.Context) {
+	response := SpreadsheetSettingsResponse{
+		SpreadsheetID:   config.AppConfig.SpreadsheetID,
+		SheetName:       config.AppConfig.SheetName,
+		SpreadsheetYear: config.AppConfig.SpreadsheetYear,
+	}
+
+	// A year mapping for the configured year takes precedence when reading data
+	if ref, ok := services.SpreadsheetForYear(response.SpreadsheetYear); ok {
+		response.SpreadsheetID = ref.SpreadsheetID
+		response.SheetName = ref.SheetName
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": SpreadsheetSettingsResponse{
-			SpreadsheetID:   config.AppConfig.SpreadsheetID,
-			SheetName:       config.AppConfig.SheetName,
-			SpreadsheetYear: config.AppConfig.SpreadsheetYear,
-		},
+		"data":    response,
 	})
 }
 
@@ -73,6 +82,12 @@ func (h *SettingsHandler) UpdateSpreadsheetSettings(c *gin.Context) {
 		sheetName = config.AppConfig.SheetName // Keep existing if not provided
 	}
 
+	before := SpreadsheetSettingsResponse{
+		SpreadsheetID:   config.AppConfig.SpreadsheetID,
+		SheetName:       config.AppConfig.SheetName,
+		SpreadsheetYear: config.AppConfig.SpreadsheetYear,
+	}
+
 	// Save to database
 	if err := upsertSetting(models.SettingSpreadsheetID, spreadsheetID); err != nil {
 		log.Printf("Failed to save spreadsheet_id setting: %v", err)
@@ -106,6 +121,16 @@ func (h *SettingsHandler) UpdateSpreadsheetSettings(c *gin.Context) {
 		return
 	}
 
+	// Keep the year mapping in step so the new spreadsheet is used for its year
+	if err := upsertYearSpreadsheet(spreadsheetYear, spreadsheetID, sheetName); err != nil {
+		log.Printf("Failed to save spreadsheet mapping for %d: %v", spreadsheetYear, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
 	// Update runtime config
 	config.AppConfig.SpreadsheetID = spreadsheetID
 	config.AppConfig.SheetName = sheetName
@@ -113,17 +138,412 @@ func (h *SettingsHandler) UpdateSpreadsheetSettings(c *gin.Context) {
 
 	log.Printf("Spreadsheet settings updated: ID=%s, Sheet=%s, Year=%d", spreadsheetID, sheetName, spreadsheetYear)
 
+	after := SpreadsheetSettingsResponse{
+		SpreadsheetID:   spreadsheetID,
+		SheetName:       sheetName,
+		SpreadsheetYear: spreadsheetYear,
+	}
+	recordAudit(c, models.AuditSettingsSpreadsheet, "setting", "spreadsheet", before, after)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Spreadsheet settings updated successfully",
-		"data": SpreadsheetSettingsResponse{
-			SpreadsheetID:   spreadsheetID,
-			SheetName:       sheetName,
-			SpreadsheetYear: spreadsheetYear,
+		"data":    after,
+	})
+}
+
+// YearSpreadsheetRequest represents the request to map a year to a spreadsheet
+type YearSpreadsheetRequest struct {
+	SpreadsheetID string `json:"spreadsheet_id"`
+	SheetName     string `json:"sheet_name"`
+}
+
+// ListYearSpreadsheets returns the spreadsheet used for each year
+// @Summary List spreadsheets per year
+// @Description Returns the year → spreadsheet mapping, including the default spreadsheet settings
+// @Tags settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Spreadsheets per year"
+// @Router /api/v1/settings/spreadsheets [get]
+func (h *SettingsHandler) ListYearSpreadsheets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    services.ListSpreadsheets(),
+	})
+}
+
+// UpdateYearSpreadsheet maps a year to a spreadsheet and sheet
+// @Summary Set spreadsheet for a year
+// @Description Sets the spreadsheet (ID or URL) and sheet name holding a year's KPI data
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param year path int true "Year"
+// @Success 200 {object} map[string]interface{} "Mapping saved"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/settings/spreadsheets/{year} [put]
+func (h *SettingsHandler) UpdateYearSpreadsheet(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 2020 || year > 2100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid year value",
+		})
+		return
+	}
+
+	var req YearSpreadsheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	spreadsheetID := extractSpreadsheetID(req.SpreadsheetID)
+	if spreadsheetID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Spreadsheet ID or URL is required",
+		})
+		return
+	}
+
+	sheetName := strings.TrimSpace(req.SheetName)
+	if sheetName == "" {
+		sheetName = config.AppConfig.SheetName
+	}
+
+	before := findYearSpreadsheet(year)
+
+	if err := upsertYearSpreadsheet(year, spreadsheetID, sheetName); err != nil {
+		log.Printf("Failed to save spreadsheet mapping for %d: %v", year, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
+	log.Printf("Spreadsheet for %d set: ID=%s, Sheet=%s", year, spreadsheetID, sheetName)
+
+	recordAudit(c, models.AuditSettingsYearUpdate, "year_spreadsheet", strconv.Itoa(year), before, findYearSpreadsheet(year))
+
+	ref, _ := services.SpreadsheetForYear(year)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Spreadsheet mapping saved",
+		"data":    ref,
+	})
+}
+
+// DeleteYearSpreadsheet removes the spreadsheet mapping of a year
+// @Summary Remove spreadsheet for a year
+// @Description Deletes a year's mapping; the configured spreadsheet year falls back to the spreadsheet settings
+// @Tags settings
+// @Produce json
+// @Security BearerAuth
+// @Param year path int true "Year"
+// @Success 200 {object} map[string]interface{} "Mapping removed"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/settings/spreadsheets/{year} [delete]
+func (h *SettingsHandler) DeleteYearSpreadsheet(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid year value",
+		})
+		return
+	}
+
+	before := findYearSpreadsheet(year)
+
+	result := database.DB.Unscoped().Where("year = ?", year).Delete(&models.YearSpreadsheet{})
+	if result.Error != nil {
+		log.Printf("Failed to delete spreadsheet mapping for %d: %v", year, result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete spreadsheet mapping",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "No spreadsheet mapped to this year",
+		})
+		return
+	}
+
+	log.Printf("Spreadsheet mapping for %d removed", year)
+	recordAudit(c, models.AuditSettingsYearDelete, "year_spreadsheet", strconv.Itoa(year), before, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Spreadsheet mapping removed",
+	})
+}
+
+// findYearSpreadsheet returns the explicit spreadsheet mapping of a year, or nil
+func findYearSpreadsheet(year int) *models.YearSpreadsheet {
+	var mapping models.YearSpreadsheet
+	result := database.DB.Where("year = ?", year).Limit(1).Find(&mapping)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+	return &mapping
+}
+
+// upsertYearSpreadsheet creates or updates the spreadsheet mapping of a year
+func upsertYearSpreadsheet(year int, spreadsheetID, sheetName string) error {
+	var mapping models.YearSpreadsheet
+	result := database.DB.Where("year = ?", year).Limit(1).Find(&mapping)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	mapping.Year = year
+	mapping.SpreadsheetID = spreadsheetID
+	mapping.SheetName = sheetName
+	return database.DB.Save(&mapping).Error
+}
+
+// KPISourceSettings represents the active KPI data source setting
+type KPISourceSettings struct {
+	Source string `json:"source"`
+}
+
+// GetKPISourceSettings returns the active KPI data source
+func (h *SettingsHandler) GetKPISourceSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": KPISourceSettings{
+			Source: config.AppConfig.KPISource,
+		},
+	})
+}
+
+// UpdateKPISourceSettings switches the KPI data source ("sheets", "upload" or "fake")
+func (h *SettingsHandler) UpdateKPISourceSettings(c *gin.Context) {
+	var req KPISourceSettings
+	if err := c.ShouldBindJSON(&req); err != nil || !services.IsValidSourceName(req.Source) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Source must be one of: sheets, upload, fake",
+		})
+		return
+	}
+
+	if req.Source == services.SourceUpload {
+		var count int64
+		database.DB.Model(&models.UploadedWorkbook{}).Where("is_active = ?", true).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "No active uploaded workbook. Upload a workbook first.",
+			})
+			return
+		}
+	}
+
+	if err := upsertSetting(models.SettingKPISource, req.Source); err != nil {
+		log.Printf("Failed to save kpi_source setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
+	before := KPISourceSettings{Source: config.AppConfig.KPISource}
+	config.AppConfig.KPISource = req.Source
+	log.Printf("KPI source updated: %s", req.Source)
+	recordAudit(c, models.AuditSettingsKPISource, "setting", "kpi_source", before, req)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "KPI source updated successfully",
+		"data":    req,
+	})
+}
+
+// ScoringSettings represents the overall score method and department weights
+type ScoringSettings struct {
+	Method            string             `json:"method"`
+	Ceiling           float64            `json:"ceiling"`
+	DepartmentWeights map[string]float64 `json:"department_weights"`
+}
+
+// GetScoringSettings returns the overall score method, attainment ceiling and department weights
+func (h *SettingsHandler) GetScoringSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    currentScoringSettings(),
+	})
+}
+
+// currentScoringSettings returns the scoring settings in effect
+func currentScoringSettings() ScoringSettings {
+	var weights []models.DepartmentWeight
+	database.DB.Order("department").Find(&weights)
+
+	departmentWeights := make(map[string]float64)
+	for _, w := range weights {
+		departmentWeights[w.Department] = w.Weight
+	}
+
+	return ScoringSettings{
+		Method:            config.AppConfig.OverallScoreMethod,
+		Ceiling:           config.AppConfig.AttainmentCeiling,
+		DepartmentWeights: departmentWeights,
+	}
+}
+
+// UpdateScoringSettings updates the overall score method, attainment ceiling and department weights.
+// Departments omitted from department_weights keep their current weight.
+func (h *SettingsHandler) UpdateScoringSettings(c *gin.Context) {
+	var req ScoringSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	if req.Method == "" {
+		req.Method = config.AppConfig.OverallScoreMethod
+	}
+	if !services.IsValidScoreMethod(req.Method) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Method must be one of: green_ratio, weighted_green_ratio, weighted_attainment",
+		})
+		return
+	}
+
+	if req.Ceiling == 0 {
+		req.Ceiling = config.AppConfig.AttainmentCeiling
+	}
+	if req.Ceiling < 1 || req.Ceiling > 999 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Ceiling must be between 1 and 999",
+		})
+		return
+	}
+
+	for department, weight := range req.DepartmentWeights {
+		if weight < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Weight for " + department + " must not be negative",
+			})
+			return
+		}
+	}
+
+	before := currentScoringSettings()
+
+	if err := upsertSetting(models.SettingScoreMethod, req.Method); err != nil {
+		log.Printf("Failed to save overall_score_method setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
+	if err := upsertSetting(models.SettingScoreCeiling, strconv.FormatFloat(req.Ceiling, 'f', -1, 64)); err != nil {
+		log.Printf("Failed to save attainment_ceiling setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
+	for department, weight := range req.DepartmentWeights {
+		var dw models.DepartmentWeight
+		database.DB.Where("department = ?", department).First(&dw)
+		dw.Department = department
+		dw.Weight = weight
+		if err := database.DB.Save(&dw).Error; err != nil {
+			log.Printf("Failed to save weight for department %s: %v", department, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to save settings",
+			})
+			return
+		}
+	}
+
+	config.AppConfig.OverallScoreMethod = req.Method
+	config.AppConfig.AttainmentCeiling = req.Ceiling
+
+	log.Printf("Scoring settings updated: method=%s ceiling=%.0f departments=%d", req.Method, req.Ceiling, len(req.DepartmentWeights))
+	recordAudit(c, models.AuditSettingsScoring, "setting", "scoring", before, currentScoringSettings())
+
+	h.GetScoringSettings(c)
+}
+
+// WeekSettings represents the week numbering setting
+type WeekSettings struct {
+	WeekStartDay string `json:"week_start_day"`
+}
+
+// GetWeekSettings returns the first day of the week used for snapshot week numbers
+func (h *SettingsHandler) GetWeekSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": WeekSettings{
+			WeekStartDay: strings.ToLower(config.AppConfig.WeekStartDay.String()),
 		},
 	})
 }
 
+// UpdateWeekSettings sets the first day of the week used for snapshot week numbers
+func (h *SettingsHandler) UpdateWeekSettings(c *gin.Context) {
+	var req WeekSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	day, ok := config.ParseWeekday(req.WeekStartDay)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Week start day must be a weekday name, e.g. monday",
+		})
+		return
+	}
+
+	value := strings.ToLower(day.String())
+	if err := upsertSetting(models.SettingWeekStartDay, value); err != nil {
+		log.Printf("Failed to save week_start_day setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save settings",
+		})
+		return
+	}
+
+	before := WeekSettings{WeekStartDay: strings.ToLower(config.AppConfig.WeekStartDay.String())}
+	config.AppConfig.WeekStartDay = day
+	log.Printf("Week start day updated: %s", value)
+	recordAudit(c, models.AuditSettingsWeek, "setting", "week", before, WeekSettings{WeekStartDay: value})
+
+	h.GetWeekSettings(c)
+}
+
 // extractSpreadsheetID extracts the spreadsheet ID from a full Google Sheets URL or returns as-is if already an ID
 func extractSpreadsheetID(input string) string {
 	input = strings.TrimSpace(input)
@@ -196,6 +616,34 @@ func LoadSettingsFromDB() {
 					log.Printf("Loaded spreadsheet_year from database: %d", yearVal)
 				}
 			}
+		case models.SettingScoreMethod:
+			if services.IsValidScoreMethod(setting.Value) {
+				config.AppConfig.OverallScoreMethod = setting.Value
+				log.Printf("Loaded overall_score_method from database: %s", setting.Value)
+			}
+		case models.SettingScoreCeiling:
+			if ceiling, err := strconv.ParseFloat(setting.Value, 64); err == nil && ceiling >= 1 && ceiling <= 999 {
+				config.AppConfig.AttainmentCeiling = ceiling
+				log.Printf("Loaded attainment_ceiling from database: %.0f", ceiling)
+			}
+		case models.SettingSnapshotCron:
+			if services.ValidateSchedule(setting.Value) == nil {
+				config.AppConfig.SnapshotSchedule = setting.Value
+				log.Printf("Loaded snapshot_schedule from database: '%s'", setting.Value)
+			}
+		case models.SettingSnapshotUser:
+			config.AppConfig.SnapshotServiceUser = setting.Value
+			log.Printf("Loaded snapshot_service_user from database: %s", setting.Value)
+		case models.SettingWeekStartDay:
+			if day, ok := config.ParseWeekday(setting.Value); ok {
+				config.AppConfig.WeekStartDay = day
+				log.Printf("Loaded week_start_day from database: %s", day)
+			}
+		case models.SettingKPISource:
+			if services.IsValidSourceName(setting.Value) {
+				config.AppConfig.KPISource = setting.Value
+				log.Printf("Loaded kpi_source from database: %s", setting.Value)
+			}
 		}
 	}
 }
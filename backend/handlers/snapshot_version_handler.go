@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSnapshotVersions returns the saved versions of a week's snapshot
+// @Summary List snapshot versions
+// @Description Returns every saved version of a week's snapshot, newest first; one of them is current
+// @Tags dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param month query int true "Month (1-12)"
+// @Param year query int true "Year"
+// @Param week query int true "Week number (1-6)"
+// @Success 200 {object} map[string]interface{} "Snapshot versions"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/dashboard/snapshot/versions [get]
+func (h *DashboardHandler) ListSnapshotVersions(c *gin.Context) {
+	month, year, week, ok := parseWeekQuery(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.dashboardService.ListSnapshotVersions(month, year, week)
+	if err != nil {
+		log.Printf("Failed to list snapshot versions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch snapshot versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    versions,
+	})
+}
+
+// DiffSnapshotVersions compares two versions of a week's snapshot per indicator
+// @Summary Diff snapshot versions
+// @Description Compares two versions of a week's snapshot per indicator. Defaults to the current version against the one before it.
+// @Tags dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param month query int true "Month (1-12)"
+// @Param year query int true "Year"
+// @Param week query int true "Week number (1-6)"
+// @Param from query int false "Older version" default(the version before "to")
+// @Param to query int false "Newer version" default(current version)
+// @Success 200 {object} map[string]interface{} "Per-indicator differences"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Version not found"
+// @Router /api/v1/dashboard/snapshot/diff [get]
+func (h *DashboardHandler) DiffSnapshotVersions(c *gin.Context) {
+	month, year, week, ok := parseWeekQuery(c)
+	if !ok {
+		return
+	}
+
+	to, ok := optionalVersionQuery(c, "to")
+	if !ok {
+		return
+	}
+	from, ok := optionalVersionQuery(c, "from")
+	if !ok {
+		return
+	}
+
+	if to == 0 {
+		current, err := h.dashboardService.GetSnapshotSummary(month, year, week)
+		if err != nil || current == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "No snapshot saved for this week",
+			})
+			return
+		}
+		to = current.Version
+	}
+	if from == 0 {
+		from = to - 1
+	}
+
+	diff, err := h.dashboardService.DiffSnapshotVersions(month, year, week, from, to)
+	if errors.Is(err, services.ErrSnapshotVersionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Snapshot version not found for this week",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to diff snapshot versions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to compare snapshot versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    diff,
+	})
+}
+
+// RestoreSnapshotVersion makes an older version of a week's snapshot current again
+// @Summary Restore snapshot version
+// @Description Saves a copy of an older version as the new current version of the week. Like a save, this
+// @Description emits webhooks, evaluates the alert rules and regenerates the week's report.
+// @Tags dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param month query int true "Month (1-12)"
+// @Param year query int true "Year"
+// @Param week query int true "Week number (1-6)"
+// @Param version query int true "Version to restore"
+// @Success 200 {object} map[string]interface{} "Version restored"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Version not found"
+// @Failure 409 {object} map[string]interface{} "Concurrent save"
+// @Router /api/v1/dashboard/snapshot/restore [post]
+func (h *DashboardHandler) RestoreSnapshotVersion(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	month, year, week, ok := parseWeekQuery(c)
+	if !ok {
+		return
+	}
+
+	version, ok := optionalVersionQuery(c, "version")
+	if !ok {
+		return
+	}
+	if version == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Version is required",
+		})
+		return
+	}
+
+	before, err := h.dashboardService.GetSnapshotSummary(month, year, week)
+	if err != nil {
+		log.Printf("Warning: Failed to summarise existing snapshot: %v", err)
+	}
+
+	newVersion, err := h.dashboardService.RestoreSnapshotVersion(month, year, week, version, user.Email)
+	if errors.Is(err, services.ErrSnapshotVersionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Snapshot version not found for this week",
+		})
+		return
+	}
+	if errors.Is(err, services.ErrSnapshotConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "This week was saved by someone else at the same time, please retry",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to restore snapshot version: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to restore snapshot version",
+		})
+		return
+	}
+
+	after, _ := h.dashboardService.GetSnapshotSummary(month, year, week)
+	recordAudit(c, models.AuditSnapshotRestore, "snapshot", weekTarget(month, year, week), before, after)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Snapshot version restored",
+		"data": gin.H{
+			"month":         month,
+			"year":          year,
+			"week_number":   week,
+			"restored_from": version,
+			"version":       newVersion,
+		},
+	})
+}
+
+// optionalVersionQuery reads a positive version number query parameter (0 if absent),
+// writing a bad request response if it is invalid
+func optionalVersionQuery(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid " + name + " version",
+		})
+		return 0, false
+	}
+	return version, true
+}
+
+// SnapshotValueRequest represents a manual entry or correction of one indicator's snapshot values
+type SnapshotValueRequest struct {
+	Month         int     `json:"month"`
+	Year          int     `json:"year"`
+	Week          int     `json:"week"`
+	IndicatorCode string  `json:"indicator_code"`
+	Target        float64 `json:"target"`
+	Performance   float64 `json:"performance"`
+	Reason        string  `json:"reason"`
+}
+
+// CorrectSnapshotValue enters or corrects an indicator's values in a week's snapshot
+// @Summary Correct snapshot value
+// @Description Sets an indicator's target and performance in a week's snapshot by hand. The percentage is
+// @Description recomputed like the dashboard, the value is flagged as manual with the reason, and the
+// @Description change is saved as a new snapshot version. Like a save, this emits webhooks, evaluates the
+// @Description alert rules and regenerates the week's report.
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SnapshotValueRequest true "Week, indicator, values and reason"
+// @Success 200 {object} map[string]interface{} "Corrected snapshot row"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Indicator not found"
+// @Failure 409 {object} map[string]interface{} "Concurrent save"
+// @Router /api/v1/dashboard/snapshot/values [put]
+func (h *DashboardHandler) CorrectSnapshotValue(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req SnapshotValueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	req.IndicatorCode = strings.TrimSpace(req.IndicatorCode)
+	req.Reason = strings.TrimSpace(req.Reason)
+	switch {
+	case req.Month < 1 || req.Month > 12:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid month value",
+		})
+		return
+	case req.Year < 2020 || req.Year > 2100:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid year value",
+		})
+		return
+	case req.IndicatorCode == "":
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Indicator code is required",
+		})
+		return
+	case math.Abs(req.Target) >= services.MaxSnapshotValue || math.Abs(req.Performance) >= services.MaxSnapshotValue:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Target and performance must be below 10,000,000,000,000",
+		})
+		return
+	case req.Reason == "" || len(req.Reason) > 255:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "A reason (up to 255 characters) is required",
+		})
+		return
+	}
+
+	before, err := h.dashboardService.GetSnapshotSummary(req.Month, req.Year, req.Week)
+	if err != nil {
+		log.Printf("Warning: Failed to summarise existing snapshot: %v", err)
+	}
+
+	row, version, err := h.dashboardService.CorrectSnapshotValue(req.Month, req.Year, req.Week,
+		req.IndicatorCode, req.Target, req.Performance, req.Reason, user.Email)
+	switch {
+	case errors.Is(err, services.ErrInvalidSnapshotWeek):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid week value for the selected month",
+		})
+		return
+	case errors.Is(err, services.ErrIndicatorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Indicator not found",
+		})
+		return
+	case errors.Is(err, services.ErrSnapshotConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "This week was saved by someone else at the same time, please retry",
+		})
+		return
+	case err != nil:
+		log.Printf("Failed to correct snapshot value: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save snapshot value",
+		})
+		return
+	}
+
+	after, _ := h.dashboardService.GetSnapshotSummary(req.Month, req.Year, req.Week)
+	recordAudit(c, models.AuditSnapshotCorrect, "snapshot", weekTarget(req.Month, req.Year, req.Week), before, gin.H{
+		"indicator_code": req.IndicatorCode,
+		"target":         req.Target,
+		"performance":    req.Performance,
+		"reason":         req.Reason,
+		"snapshot":       after,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Snapshot value saved",
+		"data": gin.H{
+			"version":  version,
+			"snapshot": row,
+		},
+	})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ThresholdHandler handles status threshold profile endpoints
+type ThresholdHandler struct{}
+
+// NewThresholdHandler creates a new ThresholdHandler instance
+func NewThresholdHandler() *ThresholdHandler {
+	return &ThresholdHandler{}
+}
+
+// ThresholdRequest represents the request to set a threshold profile
+type ThresholdRequest struct {
+	Upper  float64 `json:"upper"`
+	Middle float64 `json:"middle"`
+	Lower  float64 `json:"lower"`
+}
+
+// validate checks the bands are non-negative and ordered upper >= middle >= lower
+func (r *ThresholdRequest) validate() string {
+	if r.Lower < 0 || r.Middle < r.Lower || r.Upper < r.Middle {
+		return "Thresholds must satisfy upper >= middle >= lower >= 0"
+	}
+	if r.Upper > 999 {
+		return "Thresholds must not exceed 999"
+	}
+	return ""
+}
+
+// GetThresholds returns the global profiles and all per-indicator overrides
+// @Summary Get status thresholds
+// @Description Returns default, inverse and overall threshold profiles plus per-indicator overrides
+// @Tags settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Threshold profiles"
+// @Router /api/v1/settings/thresholds [get]
+func (h *ThresholdHandler) GetThresholds(c *gin.Context) {
+	var thresholds []models.StatusThreshold
+	if err := database.DB.Order("scope, indicator_code").Find(&thresholds).Error; err != nil {
+		log.Printf("Failed to load thresholds: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch thresholds",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    thresholds,
+	})
+}
+
+// UpdateGlobalThreshold updates the default, inverse or overall profile
+// @Summary Update global threshold profile
+// @Description Sets the bands for scope "default", "inverse" or "overall"
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param scope path string true "default, inverse or overall"
+// @Success 200 {object} map[string]interface{} "Threshold updated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/settings/thresholds/{scope} [put]
+func (h *ThresholdHandler) UpdateGlobalThreshold(c *gin.Context) {
+	scope := c.Param("scope")
+	switch scope {
+	case models.ThresholdScopeDefault, models.ThresholdScopeInverse, models.ThresholdScopeOverall:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Scope must be one of: default, inverse, overall",
+		})
+		return
+	}
+
+	h.saveThreshold(c, scope, "")
+}
+
+// UpdateIndicatorThreshold creates or updates the override for one indicator
+// @Summary Set indicator threshold override
+// @Description Sets bands for a single indicator; direction follows the indicator's inverse flag
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Indicator code"
+// @Success 200 {object} map[string]interface{} "Threshold updated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Indicator not found"
+// @Router /api/v1/settings/thresholds/indicators/{code} [put]
+func (h *ThresholdHandler) UpdateIndicatorThreshold(c *gin.Context) {
+	code := c.Param("code")
+
+	var count int64
+	database.DB.Model(&models.Indicator{}).Where("code = ?", code).Count(&count)
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Indicator not found",
+		})
+		return
+	}
+
+	h.saveThreshold(c, models.ThresholdScopeIndicator, code)
+}
+
+// DeleteIndicatorThreshold removes an indicator override so the global profile applies again
+func (h *ThresholdHandler) DeleteIndicatorThreshold(c *gin.Context) {
+	code := c.Param("code")
+
+	var before models.StatusThreshold
+	database.DB.Where("scope = ? AND indicator_code = ?", models.ThresholdScopeIndicator, code).Limit(1).Find(&before)
+
+	result := database.DB.Unscoped().
+		Where("scope = ? AND indicator_code = ?", models.ThresholdScopeIndicator, code).
+		Delete(&models.StatusThreshold{})
+	if result.Error != nil {
+		log.Printf("Failed to delete threshold for %s: %v", code, result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete threshold",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "No threshold override for this indicator",
+		})
+		return
+	}
+	log.Printf("Deleted threshold override for %s", code)
+	recordAudit(c, models.AuditSettingsThresholdDel, "threshold", thresholdTarget(models.ThresholdScopeIndicator, code), before, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Threshold override deleted successfully",
+	})
+}
+
+// saveThreshold binds the request and upserts the threshold row for scope/code
+func (h *ThresholdHandler) saveThreshold(c *gin.Context, scope, indicatorCode string) {
+	var req ThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   msg,
+		})
+		return
+	}
+
+	var threshold models.StatusThreshold
+	database.DB.Where("scope = ? AND indicator_code = ?", scope, indicatorCode).First(&threshold)
+	var before *models.StatusThreshold
+	if threshold.ID != 0 {
+		existing := threshold
+		before = &existing
+	}
+	threshold.Scope = scope
+	threshold.IndicatorCode = indicatorCode
+	threshold.Upper = req.Upper
+	threshold.Middle = req.Middle
+	threshold.Lower = req.Lower
+
+	if err := database.DB.Save(&threshold).Error; err != nil {
+		log.Printf("Failed to save %s threshold %s: %v", scope, indicatorCode, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save threshold",
+		})
+		return
+	}
+	log.Printf("Threshold updated: scope=%s code=%s bands=%.2f/%.2f/%.2f", scope, indicatorCode, req.Upper, req.Middle, req.Lower)
+	recordAudit(c, models.AuditSettingsThreshold, "threshold", thresholdTarget(scope, indicatorCode), before, threshold)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Threshold updated successfully",
+		"data":    threshold,
+	})
+}
+
+// thresholdTarget identifies a threshold row in audit events, e.g. "default" or "indicator/SALES-01"
+func thresholdTarget(scope, indicatorCode string) string {
+	if indicatorCode == "" {
+		return scope
+	}
+	return scope + "/" + indicatorCode
+}
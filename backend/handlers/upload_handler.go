@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxWorkbookSize limits uploaded workbooks to 10 MB
+const maxWorkbookSize = 10 << 20
+
+// UploadHandler handles offline KPI workbook upload endpoints
+type UploadHandler struct {
+	uploadSource *services.UploadSource
+}
+
+// NewUploadHandler creates a new UploadHandler instance
+func NewUploadHandler(uploadSource *services.UploadSource) *UploadHandler {
+	return &UploadHandler{
+		uploadSource: uploadSource,
+	}
+}
+
+// UploadWorkbook handles XLSX/CSV workbook upload and optionally makes it the active KPI source
+// @Summary Upload KPI workbook
+// @Description Uploads an XLSX or CSV export of the dashboard sheet as an offline KPI data source
+// @Tags sources
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "XLSX or CSV workbook"
+// @Param year formData int false "Year the workbook covers" default(spreadsheet year)
+// @Param sheet_name formData string false "Worksheet to read (xlsx only)"
+// @Param activate formData bool false "Serve dashboard data from this workbook" default(true)
+// @Success 200 {object} map[string]interface{} "Workbook uploaded"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/sources/upload [post]
+func (h *UploadHandler) UploadWorkbook(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	year := config.AppConfig.SpreadsheetYear
+	if yearStr := c.PostForm("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil || y < 2020 || y > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid year value",
+			})
+			return
+		}
+		year = y
+	}
+	activate := c.DefaultPostForm("activate", "true") == "true"
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No file uploaded",
+		})
+		return
+	}
+	defer file.Close()
+
+	if services.WorkbookFormat(header.Filename) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Unsupported file type, expected .xlsx or .csv",
+		})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxWorkbookSize+1))
+	if err != nil {
+		log.Printf("Failed to read workbook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to read uploaded file",
+		})
+		return
+	}
+	if len(data) > maxWorkbookSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "File is too large (max 10 MB)",
+		})
+		return
+	}
+
+	// Parse up front so invalid workbooks are rejected before they are stored
+	parsed, err := services.ParseWorkbook(header.Filename, data, c.PostForm("sheet_name"))
+	if err != nil {
+		log.Printf("Failed to parse workbook %s: %v", header.Filename, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	workbook := models.UploadedWorkbook{
+		Filename:   header.Filename,
+		Format:     parsed.Format,
+		SheetName:  parsed.SheetName,
+		Year:       year,
+		FileData:   data,
+		SizeBytes:  int64(len(data)),
+		MonthCount: len(parsed.Layout.MonthColumns),
+		KPICount:   len(parsed.Layout.KPIRows),
+		UploadedBy: user.Email,
+		UploadedAt: time.Now(),
+	}
+
+	if err := database.DB.Create(&workbook).Error; err != nil {
+		log.Printf("Failed to save workbook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save workbook",
+		})
+		return
+	}
+	log.Printf("Workbook uploaded by %s: %s (%d bytes, %d months, %d KPI rows)",
+		user.Email, workbook.Filename, workbook.SizeBytes, workbook.MonthCount, workbook.KPICount)
+
+	if activate {
+		if err := h.activateWorkbook(&workbook); err != nil {
+			log.Printf("Failed to activate workbook: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Workbook saved but could not be activated",
+			})
+			return
+		}
+	}
+	recordAudit(c, models.AuditWorkbookUpload, "workbook", strconv.Itoa(int(workbook.ID)), nil, workbook)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Workbook uploaded successfully",
+		"data":    workbook,
+	})
+}
+
+// ListWorkbooks returns all uploaded workbooks, newest first
+func (h *UploadHandler) ListWorkbooks(c *gin.Context) {
+	var workbooks []models.UploadedWorkbook
+	if err := database.DB.Omit("file_data").Order("uploaded_at desc").Find(&workbooks).Error; err != nil {
+		log.Printf("Failed to list workbooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch workbooks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    workbooks,
+	})
+}
+
+// ActivateWorkbook makes an uploaded workbook the active KPI source
+func (h *UploadHandler) ActivateWorkbook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid workbook ID",
+		})
+		return
+	}
+
+	var workbook models.UploadedWorkbook
+	if err := database.DB.Omit("file_data").First(&workbook, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Workbook not found",
+		})
+		return
+	}
+
+	before := workbook
+	if err := h.activateWorkbook(&workbook); err != nil {
+		log.Printf("Failed to activate workbook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to activate workbook",
+		})
+		return
+	}
+	recordAudit(c, models.AuditWorkbookActivate, "workbook", strconv.Itoa(id), before, workbook)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Workbook activated successfully",
+		"data":    workbook,
+	})
+}
+
+// DeleteWorkbook deletes an uploaded workbook, switching back to Google Sheets if it was active
+func (h *UploadHandler) DeleteWorkbook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid workbook ID",
+		})
+		return
+	}
+
+	var workbook models.UploadedWorkbook
+	if err := database.DB.Omit("file_data").First(&workbook, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Workbook not found",
+		})
+		return
+	}
+
+	if err := database.DB.Delete(&workbook).Error; err != nil {
+		log.Printf("Failed to delete workbook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete workbook",
+		})
+		return
+	}
+
+	if workbook.IsActive && config.AppConfig.KPISource == services.SourceUpload {
+		if err := upsertSetting(models.SettingKPISource, services.SourceSheets); err != nil {
+			log.Printf("Warning: Failed to reset kpi_source setting: %v", err)
+		}
+		config.AppConfig.KPISource = services.SourceSheets
+		log.Printf("Active workbook deleted, switched KPI source back to Google Sheets")
+	}
+	h.uploadSource.InvalidateLayout()
+	recordAudit(c, models.AuditWorkbookDelete, "workbook", strconv.Itoa(id), workbook, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Workbook deleted successfully",
+	})
+}
+
+// activateWorkbook marks the workbook as the only active upload and switches the KPI source to it
+func (h *UploadHandler) activateWorkbook(workbook *models.UploadedWorkbook) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UploadedWorkbook{}).Where("is_active = ?", true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(workbook).Update("is_active", true).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := upsertSetting(models.SettingKPISource, services.SourceUpload); err != nil {
+		return err
+	}
+
+	config.AppConfig.KPISource = services.SourceUpload
+	h.uploadSource.InvalidateLayout()
+	log.Printf("KPI source switched to uploaded workbook %s (id=%d)", workbook.Filename, workbook.ID)
+	return nil
+}
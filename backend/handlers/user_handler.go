@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler handles user administration endpoints
+type UserHandler struct{}
+
+// NewUserHandler creates a new UserHandler instance
+func NewUserHandler() *UserHandler {
+	return &UserHandler{}
+}
+
+// UpdateRoleRequest represents the request to change a user's role
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// ListUsers returns all users with their roles
+// @Summary List users
+// @Description Returns all users who have signed in, with their roles (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Users"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /api/v1/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	var users []models.User
+	if err := database.DB.Order("email").Find(&users).Error; err != nil {
+		log.Printf("Failed to list users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    users,
+	})
+}
+
+// UpdateUserRole assigns a role to a user
+// @Summary Assign user role
+// @Description Sets a user's role to viewer, editor or admin (admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Role updated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid user ID",
+		})
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !models.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Role must be viewer, editor or admin",
+		})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "User not found",
+		})
+		return
+	}
+
+	if user.Role == models.RoleAdmin && req.Role != models.RoleAdmin {
+		// Bootstrap admins get the role back on their next login
+		if config.IsAdminEmail(user.Email) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "User is a bootstrap admin (ADMIN_EMAILS) and cannot be demoted",
+			})
+			return
+		}
+
+		var adminCount int64
+		database.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&adminCount)
+		if adminCount <= 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Cannot demote the last admin",
+			})
+			return
+		}
+	}
+
+	before := gin.H{"role": user.Role}
+	if err := database.DB.Model(&user).Update("role", req.Role).Error; err != nil {
+		log.Printf("Failed to update role of user %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to update role",
+		})
+		return
+	}
+
+	log.Printf("User %s set role of %s to %s", c.GetString("email"), user.Email, req.Role)
+	recordAudit(c, models.AuditUserRole, "user", user.Email, before, gin.H{"role": req.Role})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Role updated",
+		"data":    user,
+	})
+}
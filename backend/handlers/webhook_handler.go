@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+	"weekly-dashboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles outgoing webhook subscription endpoints
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler instance
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// WebhookRequest represents the request to create or update a webhook subscription
+type WebhookRequest struct {
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Events   []string `json:"events"`
+	Secret   string   `json:"secret"`    // Generated when empty on create; kept when empty on update
+	IsActive *bool    `json:"is_active"` // Default true
+}
+
+// ListWebhooks returns all webhook subscriptions
+// @Summary List webhooks
+// @Description Returns the webhook subscriptions (without their secrets) and the available event types
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Webhook subscriptions"
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	var subscriptions []models.WebhookSubscription
+	if err := database.DB.Order("id ASC").Find(&subscriptions).Error; err != nil {
+		log.Printf("Failed to list webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch webhooks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"webhooks": subscriptions,
+			"events":   models.WebhookEvents,
+		},
+	})
+}
+
+// CreateWebhook creates a webhook subscription
+// @Summary Create webhook
+// @Description Subscribes a URL to dashboard events. Each request is a JSON POST signed with
+// @Description X-Webhook-Signature: sha256=HMAC-SHA256(secret, X-Webhook-Timestamp + "." + body).
+// @Description The secret is only returned once.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body WebhookRequest true "Name, URL, events and optional secret"
+// @Success 201 {object} map[string]interface{} "Created webhook with its secret"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	user, ok := middleware.GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	subscription := models.WebhookSubscription{IsActive: true, CreatedBy: user.Email}
+	if !applyWebhookRequest(c, &subscription, &req) {
+		return
+	}
+	if subscription.Secret == "" {
+		secret, err := services.NewWebhookSecret()
+		if err != nil {
+			log.Printf("Failed to create webhook secret: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to create webhook",
+			})
+			return
+		}
+		subscription.Secret = secret
+	}
+
+	if err := database.DB.Create(&subscription).Error; err != nil {
+		log.Printf("Failed to create webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to create webhook",
+		})
+		return
+	}
+	// IsActive defaults to true in the database, so an explicit false is written separately
+	if !subscription.IsActive {
+		database.DB.Model(&subscription).Update("is_active", false)
+	}
+
+	recordAudit(c, models.AuditWebhookCreate, "webhook", strconv.Itoa(int(subscription.ID)), nil, subscription)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Copy the secret now; it will not be shown again",
+		"data": gin.H{
+			"secret":  subscription.Secret,
+			"webhook": subscription,
+		},
+	})
+}
+
+// UpdateWebhook updates a webhook subscription
+// @Summary Update webhook
+// @Description Changes a subscription's name, URL, events, active flag or secret
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param request body WebhookRequest true "Name, URL, events, active flag and optional new secret"
+// @Success 200 {object} map[string]interface{} "Webhook updated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	subscription, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	before := *subscription
+	if !applyWebhookRequest(c, subscription, &req) {
+		return
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = before.Secret
+	}
+
+	if err := database.DB.Save(subscription).Error; err != nil {
+		log.Printf("Failed to update webhook %d: %v", subscription.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to update webhook",
+		})
+		return
+	}
+
+	after := gin.H{"webhook": subscription, "secret_changed": subscription.Secret != before.Secret}
+	recordAudit(c, models.AuditWebhookUpdate, "webhook", strconv.Itoa(int(subscription.ID)), before, after)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook updated",
+		"data":    subscription,
+	})
+}
+
+// DeleteWebhook deletes a webhook subscription; its pending deliveries are abandoned
+// @Summary Delete webhook
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} map[string]interface{} "Webhook deleted"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	subscription, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Delete(subscription).Error; err != nil {
+		log.Printf("Failed to delete webhook %d: %v", subscription.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete webhook",
+		})
+		return
+	}
+
+	recordAudit(c, models.AuditWebhookDelete, "webhook", strconv.Itoa(int(subscription.ID)), subscription, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook deleted",
+	})
+}
+
+// ListDeliveries returns the webhook delivery log
+// @Summary List webhook deliveries
+// @Description Returns the delivery log, newest first, with each attempt's outcome and payload
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhook_id query int false "Only deliveries of this webhook"
+// @Param status query string false "pending, success or failed"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Deliveries per page (max 200)" default(50)
+// @Success 200 {object} map[string]interface{} "Webhook deliveries"
+// @Router /api/v1/webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	pageSize := 50
+	if s, err := strconv.Atoi(c.Query("page_size")); err == nil && s > 0 && s <= 200 {
+		pageSize = s
+	}
+
+	var subscriptionID uint
+	if idStr := c.Query("webhook_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid webhook ID",
+			})
+			return
+		}
+		subscriptionID = uint(id)
+	}
+
+	status := c.Query("status")
+	if status != "" && status != models.WebhookDeliveryPending && status != models.WebhookDeliverySuccess && status != models.WebhookDeliveryFailed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Status must be pending, success or failed",
+		})
+		return
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(subscriptionID, status, page, pageSize)
+	if err != nil {
+		log.Printf("Failed to list webhook deliveries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch webhook deliveries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"deliveries": deliveries,
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+		},
+	})
+}
+
+// RedeliverDelivery sends a delivery's event again
+// @Summary Redeliver webhook event
+// @Description Queues the delivery's payload again as a new delivery with the same event ID
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Delivery ID"
+// @Success 202 {object} map[string]interface{} "Redelivery queued"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Router /api/v1/webhooks/deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid delivery ID",
+		})
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(uint(id))
+	if errors.Is(err, services.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Delivery or its webhook not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to redeliver webhook delivery %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to redeliver",
+		})
+		return
+	}
+
+	recordAudit(c, models.AuditWebhookRedeliver, "webhook_delivery", strconv.FormatUint(id, 10), nil, gin.H{
+		"delivery_id": delivery.ID,
+		"event":       delivery.Event,
+		"event_id":    delivery.EventID,
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Redelivery queued",
+		"data":    delivery,
+	})
+}
+
+// findWebhook loads the subscription named by the id path parameter,
+// writing an error response if it is invalid or missing
+func findWebhook(c *gin.Context) (*models.WebhookSubscription, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid webhook ID",
+		})
+		return nil, false
+	}
+
+	var subscription models.WebhookSubscription
+	if err := database.DB.First(&subscription, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Webhook not found",
+		})
+		return nil, false
+	}
+	return &subscription, true
+}
+
+// applyWebhookRequest validates a request and copies it onto a subscription,
+// writing a bad request response if it is invalid. An empty secret is left empty.
+func applyWebhookRequest(c *gin.Context, subscription *models.WebhookSubscription, req *WebhookRequest) bool {
+	fail := func(msg string) bool {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   msg,
+		})
+		return false
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return fail("Name is required and must be at most 100 characters")
+	}
+
+	rawURL := strings.TrimSpace(req.URL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(rawURL) > 500 {
+		return fail("URL must be an absolute http(s) URL of at most 500 characters")
+	}
+
+	events := []string{}
+	seen := make(map[string]bool)
+	for _, event := range req.Events {
+		event = strings.TrimSpace(event)
+		if !models.IsValidWebhookEvent(event) {
+			return fail("Unknown event type: " + event + " (expected one of " + strings.Join(models.WebhookEvents, ", ") + ")")
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return fail("At least one event type is required")
+	}
+
+	secret := strings.TrimSpace(req.Secret)
+	if secret != "" && (len(secret) < 16 || len(secret) > 100) {
+		return fail("Secret must be between 16 and 100 characters")
+	}
+
+	subscription.Name = name
+	subscription.URL = rawURL
+	subscription.Events = events
+	subscription.Secret = secret
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+	return true
+}
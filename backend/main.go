@@ -13,6 +13,7 @@ import (
 	"weekly-dashboard/database"
 	"weekly-dashboard/handlers"
 	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
 	"weekly-dashboard/services"
 
 	"github.com/gin-gonic/gin"
@@ -44,13 +45,29 @@ func main() {
 	// Initialize services
 	authService := services.NewAuthService()
 	sheetsService := services.NewSheetsService(authService)
-	dashboardService := services.NewDashboardService(sheetsService)
+	uploadSource := services.NewUploadSource()
+	kpiSource := services.NewKPICache(services.NewSourceRouter(sheetsService, uploadSource))
+	webhookService := services.NewWebhookService()
+	alertService := services.NewAlertService(webhookService)
+	dashboardService := services.NewDashboardService(kpiSource, webhookService, alertService)
+	digestService := services.NewDigestService(dashboardService)
+	snapshotScheduler := services.NewSnapshotScheduler(dashboardService, digestService)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	dashboardHandler := handlers.NewDashboardHandler(dashboardService, sheetsService)
-	screenshotHandler := handlers.NewScreenshotHandler()
+	dashboardHandler := handlers.NewDashboardHandler(dashboardService, kpiSource, digestService)
+	screenshotHandler := handlers.NewScreenshotHandler(webhookService)
 	settingsHandler := handlers.NewSettingsHandler()
+	uploadHandler := handlers.NewUploadHandler(uploadSource)
+	indicatorHandler := handlers.NewIndicatorHandler()
+	thresholdHandler := handlers.NewThresholdHandler()
+	schedulerHandler := handlers.NewSchedulerHandler(snapshotScheduler)
+	userHandler := handlers.NewUserHandler()
+	apiTokenHandler := handlers.NewAPITokenHandler(services.NewAPITokenService(), kpiSource)
+	auditHandler := handlers.NewAuditHandler()
+	digestHandler := handlers.NewDigestHandler(digestService, dashboardService, kpiSource)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	alertHandler := handlers.NewAlertHandler(alertService)
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
@@ -72,10 +89,10 @@ func main() {
 		{
 			auth.GET("/google", authHandler.GoogleLogin)
 			auth.GET("/callback", authHandler.GoogleCallback)
-			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/refresh", authHandler.Refresh)
 		}
 
-		// Protected routes
+		// Protected routes (any role)
 		protected := api.Group("")
 		protected.Use(middleware.Auth())
 		{
@@ -86,18 +103,125 @@ func main() {
 			protected.GET("/dashboard", dashboardHandler.GetDashboard)
 			protected.GET("/months", dashboardHandler.GetAvailableMonths)
 			protected.GET("/dashboard/compare", dashboardHandler.CompareDashboard)
-			protected.POST("/dashboard/snapshot", dashboardHandler.SaveSnapshot)
+			protected.GET("/dashboard/export", dashboardHandler.ExportDashboard)
 			protected.GET("/dashboard/snapshots", dashboardHandler.GetSnapshotsByMonth)
-			protected.DELETE("/dashboard/snapshot", dashboardHandler.DeleteSnapshot)
+			protected.GET("/dashboard/snapshots/export", dashboardHandler.ExportSnapshots)
+			protected.GET("/dashboard/reports", dashboardHandler.ListReports)
+			protected.GET("/dashboard/report", dashboardHandler.DownloadReport)
+			protected.GET("/dashboard/snapshot/versions", dashboardHandler.ListSnapshotVersions)
+			protected.GET("/dashboard/snapshot/diff", dashboardHandler.DiffSnapshotVersions)
+
+			// Indicators
+			protected.GET("/indicators", indicatorHandler.ListIndicators)
+			protected.GET("/indicators/:id", indicatorHandler.GetIndicator)
+
+			// Automatic snapshots
+			protected.GET("/scheduler", schedulerHandler.GetSchedulerSettings)
+			protected.GET("/scheduler/runs", schedulerHandler.GetSnapshotRuns)
 
 			// Screenshots
-			protected.POST("/dashboard/screenshot", screenshotHandler.UploadScreenshot)
 			protected.GET("/dashboard/screenshots", screenshotHandler.GetScreenshots)
 			protected.GET("/dashboard/screenshot/:id", screenshotHandler.GetScreenshotImage)
 
+			// Alerts (read-only)
+			protected.GET("/alerts", alertHandler.ListAlerts)
+			protected.GET("/alerts/rules", alertHandler.ListAlertRules)
+
 			// Settings
 			protected.GET("/settings/spreadsheet", settingsHandler.GetSpreadsheetSettings)
-			protected.PUT("/settings/spreadsheet", settingsHandler.UpdateSpreadsheetSettings)
+			protected.GET("/settings/kpi-source", settingsHandler.GetKPISourceSettings)
+			protected.GET("/settings/spreadsheets", settingsHandler.ListYearSpreadsheets)
+			protected.GET("/settings/week", settingsHandler.GetWeekSettings)
+			protected.GET("/settings/scoring", settingsHandler.GetScoringSettings)
+			protected.GET("/settings/thresholds", thresholdHandler.GetThresholds)
+
+			// Offline KPI sources
+			protected.GET("/sources/uploads", uploadHandler.ListWorkbooks)
+		}
+
+		// Browser-session routes (not reachable with an API token)
+		session := protected.Group("")
+		session.Use(middleware.RequireSession())
+		{
+			session.POST("/auth/logout", authHandler.Logout)
+			session.POST("/auth/logout-all", authHandler.LogoutAll)
+
+			// Personal API tokens
+			session.GET("/tokens", apiTokenHandler.ListTokens)
+			session.POST("/tokens", apiTokenHandler.CreateToken)
+			session.DELETE("/tokens/:id", apiTokenHandler.RevokeToken)
+		}
+
+		// Editor routes: weekly snapshots and screenshots
+		editor := protected.Group("")
+		editor.Use(middleware.RequireRole(models.RoleEditor))
+		{
+			editor.POST("/dashboard/snapshot", dashboardHandler.SaveSnapshot)
+			editor.DELETE("/dashboard/snapshot", dashboardHandler.DeleteSnapshot)
+			editor.POST("/dashboard/snapshot/restore", dashboardHandler.RestoreSnapshotVersion)
+			editor.PUT("/dashboard/snapshot/values", dashboardHandler.CorrectSnapshotValue)
+			editor.POST("/dashboard/report", dashboardHandler.GenerateReport)
+			editor.POST("/dashboard/screenshot", screenshotHandler.UploadScreenshot)
+			editor.POST("/scheduler/run", schedulerHandler.RunSnapshotNow)
+		}
+
+		// Admin routes: configuration and user management
+		admin := protected.Group("")
+		admin.Use(middleware.RequireRole(models.RoleAdmin))
+		{
+			// Users
+			admin.GET("/users", userHandler.ListUsers)
+			admin.PUT("/users/:id/role", userHandler.UpdateUserRole)
+
+			// Audit log
+			admin.GET("/audit", auditHandler.ListAuditEvents)
+
+			// Indicators
+			admin.POST("/indicators", indicatorHandler.CreateIndicator)
+			admin.PUT("/indicators/reorder", indicatorHandler.ReorderIndicators)
+			admin.PUT("/indicators/:id", indicatorHandler.UpdateIndicator)
+			admin.POST("/indicators/:id/activate", indicatorHandler.ActivateIndicator)
+			admin.POST("/indicators/:id/deactivate", indicatorHandler.DeactivateIndicator)
+			admin.DELETE("/indicators/:id", indicatorHandler.DeleteIndicator)
+
+			// Automatic snapshots
+			admin.PUT("/scheduler", schedulerHandler.UpdateSchedulerSettings)
+
+			// Digest emails
+			admin.GET("/digest", digestHandler.GetDigestSettings)
+			admin.POST("/digest/recipients", digestHandler.AddDigestRecipient)
+			admin.DELETE("/digest/recipients/:id", digestHandler.DeleteDigestRecipient)
+			admin.GET("/digest/preview", digestHandler.PreviewDigest)
+			admin.POST("/digest/send", digestHandler.SendDigest)
+
+			// Outgoing webhooks
+			admin.GET("/webhooks", webhookHandler.ListWebhooks)
+			admin.POST("/webhooks", webhookHandler.CreateWebhook)
+			admin.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+			admin.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+			admin.GET("/webhooks/deliveries", webhookHandler.ListDeliveries)
+			admin.POST("/webhooks/deliveries/:id/redeliver", webhookHandler.RedeliverDelivery)
+
+			// Alert rules
+			admin.POST("/alerts/rules", alertHandler.CreateAlertRule)
+			admin.PUT("/alerts/rules/:id", alertHandler.UpdateAlertRule)
+			admin.DELETE("/alerts/rules/:id", alertHandler.DeleteAlertRule)
+
+			// Settings
+			admin.PUT("/settings/spreadsheet", settingsHandler.UpdateSpreadsheetSettings)
+			admin.PUT("/settings/kpi-source", settingsHandler.UpdateKPISourceSettings)
+			admin.PUT("/settings/spreadsheets/:year", settingsHandler.UpdateYearSpreadsheet)
+			admin.DELETE("/settings/spreadsheets/:year", settingsHandler.DeleteYearSpreadsheet)
+			admin.PUT("/settings/week", settingsHandler.UpdateWeekSettings)
+			admin.PUT("/settings/scoring", settingsHandler.UpdateScoringSettings)
+			admin.PUT("/settings/thresholds/indicators/:code", thresholdHandler.UpdateIndicatorThreshold)
+			admin.DELETE("/settings/thresholds/indicators/:code", thresholdHandler.DeleteIndicatorThreshold)
+			admin.PUT("/settings/thresholds/:scope", thresholdHandler.UpdateGlobalThreshold)
+
+			// Offline KPI sources
+			admin.POST("/sources/upload", uploadHandler.UploadWorkbook)
+			admin.PUT("/sources/uploads/:id/activate", uploadHandler.ActivateWorkbook)
+			admin.DELETE("/sources/uploads/:id", uploadHandler.DeleteWorkbook)
 		}
 
 		// Public screenshot image endpoint (no auth required for image viewing)
@@ -124,6 +248,11 @@ func main() {
 		}
 	}()
 
+	// Start automatic snapshot and digest schedulers and webhook deliveries
+	snapshotScheduler.Start()
+	digestService.Start()
+	webhookService.Start()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -131,6 +260,11 @@ func main() {
 
 	log.Println("Shutting down server...")
 
+	// Stop scheduling new snapshots and digests and wait for running ones
+	snapshotScheduler.Stop()
+	digestService.Stop()
+	webhookService.Stop()
+
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiTokenTouchInterval limits how often last-used tracking writes to the database
+const apiTokenTouchInterval = time.Minute
+
+// HashAPIToken returns the hex SHA-256 of a raw API token; only hashes are stored
+func HashAPIToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticateAPIToken validates a personal API token and loads its owner.
+// It writes the error response itself and returns false when the request must stop.
+func authenticateAPIToken(c *gin.Context, raw string) (*models.User, *models.APIToken, bool) {
+	var token models.APIToken
+	result := database.DB.Where("token_hash = ?", HashAPIToken(raw)).Limit(1).Find(&token)
+	if result.Error != nil || result.RowsAffected == 0 || !token.IsActive() {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Invalid, expired or revoked API token",
+		})
+		c.Abort()
+		return nil, nil, false
+	}
+
+	if !token.Allows(c.Request.Method) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "This API token only has the " + token.Scope + " scope",
+		})
+		c.Abort()
+		return nil, nil, false
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, token.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not found",
+		})
+		c.Abort()
+		return nil, nil, false
+	}
+
+	// Tokens stop working once the owner may no longer sign in (deny list, allowed domains)
+	if config.LoginRejection(user.Email, true) != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "This account is no longer allowed to sign in",
+		})
+		c.Abort()
+		return nil, nil, false
+	}
+
+	touchAPIToken(&token, c.ClientIP())
+	return &user, &token, true
+}
+
+// touchAPIToken records the token's last use, at most once per apiTokenTouchInterval
+func touchAPIToken(token *models.APIToken, ip string) {
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < apiTokenTouchInterval && token.LastUsedIP == ip {
+		return
+	}
+	database.DB.Model(&models.APIToken{}).Where("id = ?", token.ID).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+	token.LastUsedAt = &now
+	token.LastUsedIP = ip
+}
+
+// GetCurrentAPIToken retrieves the API token the request was authenticated with, if any
+func GetCurrentAPIToken(c *gin.Context) (*models.APIToken, bool) {
+	token, exists := c.Get("apiToken")
+	if !exists {
+		return nil, false
+	}
+	t, ok := token.(*models.APIToken)
+	return t, ok
+}
+
+// RequireSession returns a middleware that rejects requests authenticated with an API token,
+// for endpoints that manage the user's own sign-in credentials. It must run after Auth.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetCurrentAPIToken(c); ok {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "This endpoint requires a browser session, not an API token",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
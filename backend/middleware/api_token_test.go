@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"weekly-dashboard/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHashAPIToken(t *testing.T) {
+	a := HashAPIToken(models.APITokenPrefix + "abc")
+	if len(a) != 64 {
+		t.Errorf("hash length = %d, want 64 hex characters", len(a))
+	}
+	if a != HashAPIToken(models.APITokenPrefix+"abc") {
+		t.Error("hash is not deterministic")
+	}
+	if a == HashAPIToken(models.APITokenPrefix+"abd") {
+		t.Error("different tokens hash alike")
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      *models.APIToken
+		wantStatus int
+	}{
+		{"browser session", nil, http.StatusOK},
+		{"API token", &models.APIToken{Scope: models.ScopeWrite}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.token != nil {
+					c.Set("apiToken", tt.token)
+				}
+			})
+			router.POST("/tokens", RequireSession(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tokens", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"weekly-dashboard/config"
 	"weekly-dashboard/database"
@@ -12,14 +14,17 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Claims represents JWT claims
+// Claims represents JWT claims. RegisteredClaims.ID carries the token ID (jti)
+// used to revoke a single access token; SessionID is the refresh-token family
+// the access token was issued from.
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// Auth returns a middleware that validates JWT tokens
+// Auth returns a middleware that validates JWT tokens and personal API tokens
 func Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
@@ -46,13 +51,29 @@ func Auth() gin.HandlerFunc {
 
 		tokenString := parts[1]
 
+		// Personal API tokens for scripted access
+		if strings.HasPrefix(tokenString, models.APITokenPrefix) {
+			user, apiToken, ok := authenticateAPIToken(c, tokenString)
+			if !ok {
+				return
+			}
+
+			c.Set("user", *user)
+			c.Set("userID", user.ID)
+			c.Set("email", user.Email)
+			c.Set("apiToken", apiToken)
+
+			c.Next()
+			return
+		}
+
 		// Parse and validate token
 		claims := &Claims{}
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 			return []byte(config.AppConfig.JWTSecret), nil
 		})
 
-		if err != nil || !token.Valid {
+		if err != nil || !token.Valid || claims.ID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"success": false,
 				"error":   "Invalid or expired token",
@@ -61,6 +82,26 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens revoked by logout; fail closed if the revocation list cannot be read
+		var revoked int64
+		if err := database.DB.Model(&models.RevokedToken{}).Where("jti = ?", claims.ID).Count(&revoked).Error; err != nil {
+			log.Printf("Failed to check token revocation: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "Unable to verify session, please try again",
+			})
+			c.Abort()
+			return
+		}
+		if revoked > 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Session has been logged out",
+			})
+			c.Abort()
+			return
+		}
+
 		// Get user from database
 		var user models.User
 		if err := database.DB.First(&user, claims.UserID).Error; err != nil {
@@ -72,15 +113,36 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens issued before the user logged out all sessions (iat has second precision)
+		if user.SessionsRevokedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Before(user.SessionsRevokedAt.Truncate(time.Second))) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Session has been logged out",
+			})
+			c.Abort()
+			return
+		}
+
 		// Set user in context
 		c.Set("user", user)
 		c.Set("userID", user.ID)
 		c.Set("email", user.Email)
+		c.Set("claims", claims)
 
 		c.Next()
 	}
 }
 
+// GetCurrentClaims retrieves the validated JWT claims from context
+func GetCurrentClaims(c *gin.Context) (*Claims, bool) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	cl, ok := claims.(*Claims)
+	return cl, ok
+}
+
 // GetCurrentUser retrieves the current user from context
 func GetCurrentUser(c *gin.Context) (*models.User, bool) {
 	user, exists := c.Get("user")
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// signTestJWT signs access token claims with the given secret
+func signTestJWT(t *testing.T, secret, jti string, expiresIn time.Duration) string {
+	t.Helper()
+	now := time.Now()
+	claims := &Claims{
+		UserID: 1,
+		Email:  "user@corp.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// serveAuth runs a request with the given Authorization header through Auth
+func serveAuth(header string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/me", Auth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthRejectsMalformedTokens(t *testing.T) {
+	secret := config.AppConfig.JWTSecret
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"no header", ""},
+		{"not a bearer token", "Basic dXNlcjpwYXNz"},
+		{"extra parts", "Bearer a b"},
+		{"garbage", "Bearer not-a-jwt"},
+		{"wrong secret", "Bearer " + signTestJWT(t, "other-secret", "jti-1", time.Minute)},
+		{"expired", "Bearer " + signTestJWT(t, secret, "jti-1", -time.Minute)},
+		{"no token ID to revoke it by", "Bearer " + signTestJWT(t, secret, "", time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serveAuth(tt.header); w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestAuthFailsClosedWhenRevocationCheckFails(t *testing.T) {
+	// A database nobody listens on: the revocation lookup errors instead of finding nothing
+	unreachable, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=x dbname=x sslmode=disable connect_timeout=1"), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database handle: %v", err)
+	}
+	saved := database.DB
+	database.DB = unreachable
+	defer func() { database.DB = saved }()
+
+	w := serveAuth("Bearer " + signTestJWT(t, config.AppConfig.JWTSecret, "jti-1", time.Minute))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
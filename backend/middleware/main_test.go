@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"os"
+	"testing"
+
+	"weekly-dashboard/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMain runs the middleware tests in gin's test mode with a fixed JWT secret
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	config.AppConfig = &config.Config{JWTSecret: "test-secret"}
+	os.Exit(m.Run())
+}
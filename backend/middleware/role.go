@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole returns a middleware that only lets users with at least the given role through.
+// It must run after Auth.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := GetCurrentUser(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		if !user.HasRole(role) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "This action requires the " + role + " role",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import "time"
+
+// Alert rule conditions
+const (
+	AlertStatusTransition = "status_transition" // Status changed to ToStatus (from FromStatus, if set)
+	AlertPercentageBelow  = "percentage_below"  // Percentage < Threshold
+	AlertVarianceBelow    = "variance_below"    // Schedule variance (%) < Threshold
+	AlertWoWDrop          = "wow_drop"          // Worsened by more than Threshold points since the previous snapshot
+	AlertConsecutiveRed   = "consecutive_red"   // Red for the last Weeks snapshots, the current week included
+)
+
+// AlertConditions lists every rule condition
+var AlertConditions = []string{
+	AlertStatusTransition,
+	AlertPercentageBelow,
+	AlertVarianceBelow,
+	AlertWoWDrop,
+	AlertConsecutiveRed,
+}
+
+// IsValidAlertCondition checks if the condition exists
+func IsValidAlertCondition(condition string) bool {
+	for _, c := range AlertConditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// Alert states
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// AlertRule watches one indicator, one department's indicators, or all indicators
+// (when both IndicatorCode and Department are empty) for a condition
+type AlertRule struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	Name          string    `gorm:"size:100;not null" json:"name"`
+	IndicatorCode string    `gorm:"size:50;not null;default:'';index" json:"indicator_code"`
+	Department    string    `gorm:"size:50;not null;default:''" json:"department"`
+	Condition     string    `gorm:"size:30;not null" json:"condition"`
+	Threshold     float64   `gorm:"not null;default:0" json:"threshold"`            // percentage_below, variance_below, wow_drop
+	FromStatus    string    `gorm:"size:20;not null;default:''" json:"from_status"` // status_transition, empty = any other status
+	ToStatus      string    `gorm:"size:20;not null;default:''" json:"to_status"`   // status_transition
+	Weeks         int       `gorm:"not null;default:0" json:"weeks"`                // consecutive_red
+	IsActive      bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedBy     string    `gorm:"size:100" json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for AlertRule model
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// Applies reports whether the rule watches an indicator
+func (r *AlertRule) Applies(code, department string) bool {
+	if r.IndicatorCode != "" && r.IndicatorCode != code {
+		return false
+	}
+	if r.Department != "" && r.Department != department {
+		return false
+	}
+	return true
+}
+
+// Alert is a rule firing for one indicator. While the condition holds the alert stays firing
+// and is updated in place; once it no longer holds the alert is resolved, and a later breach
+// opens a new alert. At most one alert per rule and indicator fires at a time.
+type Alert struct {
+	ID              uint       `gorm:"primarykey" json:"id"`
+	RuleID          uint       `gorm:"not null;index;uniqueIndex:idx_alerts_firing,where:state = 'firing'" json:"rule_id"`
+	IndicatorCode   string     `gorm:"size:50;not null;index;uniqueIndex:idx_alerts_firing,where:state = 'firing'" json:"indicator_code"`
+	Department      string     `gorm:"size:50;not null" json:"department"`
+	IndicatorName   string     `gorm:"size:100;not null" json:"indicator_name"`
+	State           string     `gorm:"size:20;not null;index" json:"state"`
+	Message         string     `gorm:"size:255;not null" json:"message"`
+	Value           float64    `json:"value"` // The value that breached the rule, as of the last evaluation
+	Month           int        `json:"month"` // Week of the last evaluation
+	Year            int        `json:"year"`
+	Week            int        `json:"week"`
+	FiredAt         time.Time  `gorm:"not null;index" json:"fired_at"`
+	ResolvedAt      *time.Time `json:"resolved_at"`
+	LastEvaluatedAt time.Time  `gorm:"not null" json:"last_evaluated_at"`
+}
+
+// TableName specifies the table name for Alert model
+func (Alert) TableName() string {
+	return "alerts"
+}
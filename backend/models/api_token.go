@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// APITokenPrefix starts every personal API token, telling it apart from a JWT
+const APITokenPrefix = "wdb_"
+
+// API token scopes
+const (
+	ScopeRead  = "read"  // GET requests only
+	ScopeWrite = "write" // Everything the owner's role allows
+)
+
+// IsValidScope reports whether scope is a known API token scope
+func IsValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeWrite
+}
+
+// APIToken is a personal access token for scripted API access. Only the SHA-256 of
+// the token is stored; the raw value is shown once, when the token is created.
+type APIToken struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"` // Leading characters, to recognise the token
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scope      string     `gorm:"size:20;not null" json:"scope"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:64" json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for APIToken model
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// IsActive reports whether the token can still be used
+func (t *APIToken) IsActive() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+// Allows reports whether the token's scope permits a request with the given HTTP method
+func (t *APIToken) Allows(method string) bool {
+	if t.Scope == ScopeWrite {
+		return true
+	}
+	return method == "GET" || method == "HEAD"
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPITokenIsActive(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		token APIToken
+		want  bool
+	}{
+		{"valid", APIToken{ExpiresAt: now.Add(time.Hour)}, true},
+		{"expired", APIToken{ExpiresAt: now.Add(-time.Second)}, false},
+		{"revoked", APIToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &now}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.token.IsActive(); got != tt.want {
+			t.Errorf("%s: IsActive() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAPITokenAllows(t *testing.T) {
+	tests := []struct {
+		scope  string
+		method string
+		want   bool
+	}{
+		{ScopeRead, "GET", true},
+		{ScopeRead, "HEAD", true},
+		{ScopeRead, "POST", false},
+		{ScopeRead, "DELETE", false},
+		{ScopeWrite, "GET", true},
+		{ScopeWrite, "POST", true},
+		{ScopeWrite, "DELETE", true},
+		{"", "GET", true},
+		{"", "PUT", false},
+	}
+
+	for _, tt := range tests {
+		token := APIToken{Scope: tt.scope}
+		if got := token.Allows(tt.method); got != tt.want {
+			t.Errorf("%q token Allows(%s) = %v, want %v", tt.scope, tt.method, got, tt.want)
+		}
+	}
+}
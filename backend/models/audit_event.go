@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditSnapshotSave          = "snapshot.save"
+	AuditSnapshotDelete        = "snapshot.delete"
+	AuditSnapshotRestore       = "snapshot.restore"
+	AuditSnapshotCorrect       = "snapshot.correct"
+	AuditScreenshotUpload      = "screenshot.upload"
+	AuditReportGenerate        = "report.generate"
+	AuditSettingsSpreadsheet   = "settings.spreadsheet.update"
+	AuditSettingsYearUpdate    = "settings.year_spreadsheet.update"
+	AuditSettingsYearDelete    = "settings.year_spreadsheet.delete"
+	AuditSettingsKPISource     = "settings.kpi_source.update"
+	AuditSettingsScoring       = "settings.scoring.update"
+	AuditSettingsWeek          = "settings.week.update"
+	AuditSettingsThreshold     = "settings.threshold.update"
+	AuditSettingsThresholdDel  = "settings.threshold.delete"
+	AuditSchedulerUpdate       = "scheduler.update"
+	AuditSchedulerRun          = "scheduler.run"
+	AuditIndicatorCreate       = "indicator.create"
+	AuditIndicatorUpdate       = "indicator.update"
+	AuditIndicatorDelete       = "indicator.delete"
+	AuditIndicatorReorder      = "indicator.reorder"
+	AuditWorkbookUpload        = "workbook.upload"
+	AuditWorkbookActivate      = "workbook.activate"
+	AuditWorkbookDelete        = "workbook.delete"
+	AuditAuthLogin             = "auth.login"
+	AuditAuthLogout            = "auth.logout"
+	AuditAuthLogoutAll         = "auth.logout_all"
+	AuditUserRole              = "user.role.update"
+	AuditAPITokenCreate        = "api_token.create"
+	AuditAPITokenRevoke        = "api_token.revoke"
+	AuditDigestRecipientAdd    = "digest.recipient.create"
+	AuditDigestRecipientDelete = "digest.recipient.delete"
+	AuditWebhookCreate         = "webhook.create"
+	AuditWebhookUpdate         = "webhook.update"
+	AuditWebhookDelete         = "webhook.delete"
+	AuditWebhookRedeliver      = "webhook.redeliver"
+	AuditDigestSend            = "digest.send"
+	AuditAlertRuleCreate       = "alert_rule.create"
+	AuditAlertRuleUpdate       = "alert_rule.update"
+	AuditAlertRuleDelete       = "alert_rule.delete"
+)
+
+// AuditEvent records who changed what, with a JSON summary of the target before and after
+type AuditEvent struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	ActorID    *uint     `gorm:"index" json:"actor_id"` // Nil for system actions
+	ActorEmail string    `gorm:"size:100;index" json:"actor_email"`
+	APITokenID *uint     `json:"api_token_id,omitempty"` // Set when the actor used a personal API token
+	Action     string    `gorm:"size:50;not null;index" json:"action"`
+	TargetType string    `gorm:"size:50;index" json:"target_type"`
+	TargetID   string    `gorm:"size:100;index" json:"target_id"`
+	Before     string    `gorm:"type:text" json:"-"` // JSON summary, empty when the target did not exist
+	After      string    `gorm:"type:text" json:"-"` // JSON summary, empty when the target was removed
+	IPAddress  string    `gorm:"size:64" json:"ip_address"`
+	CreatedAt  time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+// TableName specifies the table name for AuditEvent model
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// MarshalJSON embeds the before/after summaries as JSON rather than strings
+func (e AuditEvent) MarshalJSON() ([]byte, error) {
+	type event AuditEvent
+	return json.Marshal(struct {
+		event
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}{event(e), rawJSON(e.Before), rawJSON(e.After)})
+}
+
+// rawJSON returns a stored summary as raw JSON, or null when empty or malformed
+func rawJSON(s string) json.RawMessage {
+	if s == "" || !json.Valid([]byte(s)) {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s)
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// DepartmentWeight scales the weight of every indicator in a department
+// when calculating the weighted overall performance score
+type DepartmentWeight struct {
+	gorm.Model
+	Department string  `gorm:"size:50;uniqueIndex;not null" json:"department"`
+	Weight     float64 `gorm:"type:decimal(7,2);not null" json:"weight"`
+}
+
+// TableName specifies the table name for DepartmentWeight model
+func (DepartmentWeight) TableName() string {
+	return "department_weights"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DigestRecipient receives the weekly digest email. A recipient with a department only
+// gets that department's indicators; an empty department means the whole dashboard.
+type DigestRecipient struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	Email      string    `gorm:"size:100;not null;uniqueIndex:idx_digest_recipients_email_department" json:"email"`
+	Department string    `gorm:"size:50;not null;default:'';uniqueIndex:idx_digest_recipients_email_department" json:"department"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for DigestRecipient model
+func (DigestRecipient) TableName() string {
+	return "digest_recipients"
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Login audit outcomes
+const (
+	LoginAccepted = "accepted"
+	LoginRejected = "rejected"
+)
+
+// LoginAudit records a completed Google sign-in and whether it was let through
+type LoginAudit struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Email       string    `gorm:"size:100;not null;index" json:"email"`
+	Outcome     string    `gorm:"size:20;not null;index" json:"outcome"`
+	Reason      string    `gorm:"size:50" json:"reason"` // Rejection reason code, empty when accepted
+	IPAddress   string    `gorm:"size:64" json:"ip_address"`
+	UserAgent   string    `gorm:"size:255" json:"user_agent"`
+	AttemptedAt time.Time `gorm:"not null;index" json:"attempted_at"`
+}
+
+// TableName specifies the table name for LoginAudit model
+func (LoginAudit) TableName() string {
+	return "login_audits"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is one link in a rotating refresh-token chain. Every login starts a
+// family; each refresh consumes the presented token and issues the next one in the
+// same family. Presenting a consumed token again revokes the whole family.
+type RefreshToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"` // SHA-256 of the cookie value
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	FamilyID  string     `gorm:"size:64;not null;index" json:"family_id"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`    // Set when rotated
+	RevokedAt *time.Time `json:"revoked_at"` // Set on logout or reuse detection
+	IPAddress string     `gorm:"size:64" json:"ip_address"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
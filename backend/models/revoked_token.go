@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// RevokedToken blocks a single JWT (by its jti) until the token would have expired anyway
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	JTI       string    `gorm:"column:jti;size:64;not null;uniqueIndex" json:"jti"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"` // Safe to purge after this
+	RevokedAt time.Time `gorm:"not null" json:"revoked_at"`
+}
+
+// TableName specifies the table name for RevokedToken model
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
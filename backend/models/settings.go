@@ -21,4 +21,10 @@ const (
 	SettingSpreadsheetID   = "spreadsheet_id"
 	SettingSheetName       = "sheet_name"
 	SettingSpreadsheetYear = "spreadsheet_year"
+	SettingKPISource       = "kpi_source"
+	SettingScoreMethod     = "overall_score_method"
+	SettingScoreCeiling    = "attainment_ceiling"
+	SettingSnapshotCron    = "snapshot_schedule"
+	SettingSnapshotUser    = "snapshot_service_user"
+	SettingWeekStartDay    = "week_start_day"
 )
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Snapshot run triggers and statuses
+const (
+	SnapshotRunScheduled = "schedule"
+	SnapshotRunManual    = "manual"
+
+	SnapshotRunRunning = "running"
+	SnapshotRunSuccess = "success"
+	SnapshotRunFailed  = "failed"
+)
+
+// SnapshotRun records the outcome of one automatic (or "run now") snapshot
+type SnapshotRun struct {
+	gorm.Model
+	Trigger        string     `gorm:"size:20;not null" json:"trigger"` // "schedule" or "manual"
+	TriggeredBy    string     `gorm:"size:100" json:"triggered_by"`    // Email of the user for manual runs
+	ServiceUser    string     `gorm:"size:100" json:"service_user"`    // Account whose token was used
+	Status         string     `gorm:"size:20;not null;index" json:"status"`
+	Month          int        `json:"month"`
+	Year           int        `json:"year"`
+	WeekNumber     int        `json:"week_number"`
+	IndicatorCount int        `json:"indicator_count"`
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt      time.Time  `gorm:"not null;index" json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+// TableName specifies the table name for SnapshotRun model
+func (SnapshotRun) TableName() string {
+	return "snapshot_runs"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Threshold scopes
+const (
+	ThresholdScopeDefault   = "default"   // Global bands for normal (higher is better) indicators
+	ThresholdScopeInverse   = "inverse"   // Global bands for inverse (lower is better) indicators
+	ThresholdScopeIndicator = "indicator" // Override for a single indicator
+	ThresholdScopeOverall   = "overall"   // Bands for the overall performance gauge
+)
+
+// StatusThreshold defines the percentage cut-offs used to grade a KPI.
+// Normal metrics: >Upper supergreen, >Middle green, >Lower yellow, otherwise red.
+// Inverse metrics: >=Upper red, >=Middle yellow, >=Lower green, otherwise supergreen.
+// The overall gauge only uses Middle (green) and Lower (yellow).
+type StatusThreshold struct {
+	gorm.Model
+	Scope         string  `gorm:"size:20;not null;uniqueIndex:idx_status_threshold_scope" json:"scope"`
+	IndicatorCode string  `gorm:"size:50;uniqueIndex:idx_status_threshold_scope" json:"indicator_code"` // Set when Scope is "indicator"
+	Upper         float64 `gorm:"type:decimal(7,2);not null" json:"upper"`
+	Middle        float64 `gorm:"type:decimal(7,2);not null" json:"middle"`
+	Lower         float64 `gorm:"type:decimal(7,2);not null" json:"lower"`
+}
+
+// TableName specifies the table name for StatusThreshold model
+func (StatusThreshold) TableName() string {
+	return "status_thresholds"
+}
+
+// GetDefaultThresholds returns the built-in 100/85/55 bands
+func GetDefaultThresholds() []StatusThreshold {
+	return []StatusThreshold{
+		{Scope: ThresholdScopeDefault, Upper: 100, Middle: 85, Lower: 55},
+		{Scope: ThresholdScopeInverse, Upper: 100, Middle: 85, Lower: 55},
+		{Scope: ThresholdScopeOverall, Upper: 100, Middle: 85, Lower: 55},
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UploadedWorkbook stores an exported DashboardTemplate workbook (XLSX or CSV)
+// used as an offline KPI data source instead of the live spreadsheet
+type UploadedWorkbook struct {
+	gorm.Model
+	Filename   string    `gorm:"size:255;not null" json:"filename"`
+	Format     string    `gorm:"size:10;not null" json:"format"` // "xlsx" or "csv"
+	SheetName  string    `gorm:"size:100" json:"sheet_name"`     // Worksheet the rows were read from (xlsx only)
+	Year       int       `gorm:"not null;index" json:"year"`
+	FileData   []byte    `gorm:"type:bytea;not null" json:"-"` // Original file, re-parsed on load
+	SizeBytes  int64     `gorm:"not null" json:"size_bytes"`
+	MonthCount int       `json:"month_count"` // Months discovered in the header row
+	KPICount   int       `json:"kpi_count"`   // KPI names discovered in column C
+	UploadedBy string    `gorm:"size:100" json:"uploaded_by"`
+	UploadedAt time.Time `gorm:"not null" json:"uploaded_at"`
+	IsActive   bool      `gorm:"default:false;index" json:"is_active"`
+}
+
+// TableName specifies the table name for UploadedWorkbook model
+func (UploadedWorkbook) TableName() string {
+	return "uploaded_workbooks"
+}
@@ -6,19 +6,47 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles, from least to most privileged
+const (
+	RoleViewer = "viewer" // Read dashboards, snapshots and settings
+	RoleEditor = "editor" // Also save and delete snapshots and upload screenshots
+	RoleAdmin  = "admin"  // Also change settings, indicators and user roles
+)
+
+// roleRanks orders the roles so a higher role includes the lower ones
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// IsValidRole reports whether role is a known user role
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
 // User represents a user authenticated via Google OAuth
 type User struct {
 	gorm.Model
 	Email        string    `gorm:"size:100;uniqueIndex;not null" json:"email"`
 	Name         string    `gorm:"size:100" json:"name"`
 	Picture      string    `gorm:"size:255" json:"picture"`
+	Role         string    `gorm:"size:20;not null;default:viewer" json:"role"`
 	AccessToken  string    `gorm:"type:text" json:"-"`
 	RefreshToken string    `gorm:"type:text" json:"-"`
 	TokenExpiry  time.Time `json:"-"`
 	LastLogin    time.Time `json:"last_login"`
+	// JWTs issued before this moment are rejected ("log out all sessions")
+	SessionsRevokedAt *time.Time `json:"-"`
 }
 
 // TableName specifies the table name for User model
 func (User) TableName() string {
 	return "users"
 }
+
+// HasRole reports whether the user's role is at least the given role
+func (u *User) HasRole(role string) bool {
+	return roleRanks[u.Role] >= roleRanks[role]
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types
+const (
+	WebhookEventSnapshotSaved      = "snapshot.saved"
+	WebhookEventSnapshotDeleted    = "snapshot.deleted"
+	WebhookEventScreenshotUploaded = "screenshot.uploaded"
+	WebhookEventKPIRed             = "kpi.red"
+	WebhookEventAlertFiring        = "alert.firing"
+	WebhookEventAlertResolved      = "alert.resolved"
+)
+
+// WebhookEvents lists every event a subscription can receive
+var WebhookEvents = []string{
+	WebhookEventSnapshotSaved,
+	WebhookEventSnapshotDeleted,
+	WebhookEventScreenshotUploaded,
+	WebhookEventKPIRed,
+	WebhookEventAlertFiring,
+	WebhookEventAlertResolved,
+}
+
+// IsValidWebhookEvent checks if the event type exists
+func IsValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending = "pending" // Waiting for its first or next attempt
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed" // Gave up after the last attempt
+)
+
+// WebhookSubscription sends the selected dashboard events to a URL as signed JSON
+type WebhookSubscription struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	URL       string    `gorm:"size:500;not null" json:"url"`
+	Events    []string  `gorm:"type:text;serializer:json;not null" json:"events"`
+	Secret    string    `gorm:"size:100;not null" json:"-"` // HMAC-SHA256 key for X-Webhook-Signature
+	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedBy string    `gorm:"size:100" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for WebhookSubscription model
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribes reports whether the subscription receives an event type
+func (w *WebhookSubscription) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent (or being retried) to one subscription
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	SubscriptionID uint       `gorm:"not null;index" json:"subscription_id"`
+	Event          string     `gorm:"size:50;not null;index" json:"event"`
+	EventID        string     `gorm:"size:64;not null;index" json:"event_id"` // Same for redeliveries, so receivers can deduplicate
+	Payload        string     `gorm:"type:text;not null" json:"-"`
+	Status         string     `gorm:"size:20;not null;index" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int        `json:"response_status"` // HTTP status of the last attempt, 0 if none
+	ResponseBody   string     `gorm:"type:text" json:"response_body,omitempty"`
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	RedeliveryOf   *uint      `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// MarshalJSON embeds the payload as JSON rather than a string
+func (d WebhookDelivery) MarshalJSON() ([]byte, error) {
+	type delivery WebhookDelivery
+	return json.Marshal(struct {
+		delivery
+		Payload json.RawMessage `json:"payload"`
+	}{delivery(d), rawJSON(d.Payload)})
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WeeklyReport is the PDF report of a week's dashboard, generated by the server when the
+// week's snapshot is saved (or on request). There is one report per week; regenerating replaces it.
+// (month, year, week) is unique; see database.WeeklyReportWeekIndex.
+type WeeklyReport struct {
+	gorm.Model
+	Month           int       `gorm:"not null;index" json:"month"`
+	Year            int       `gorm:"not null;index" json:"year"`
+	Week            int       `gorm:"not null" json:"week"`
+	Filename        string    `gorm:"size:100;not null" json:"filename"`
+	PDFData         []byte    `gorm:"type:bytea;not null" json:"-"` // Served by the download endpoint only
+	SizeBytes       int64     `gorm:"not null" json:"size_bytes"`
+	SnapshotVersion int       `gorm:"not null;default:0" json:"snapshot_version"` // Current snapshot version when generated, 0 if none
+	GeneratedBy     string    `gorm:"size:100;not null;default:''" json:"generated_by"`
+	GeneratedAt     time.Time `gorm:"not null" json:"generated_at"`
+}
+
+// TableName specifies the table name for WeeklyReport model
+func (WeeklyReport) TableName() string {
+	return "weekly_reports"
+}
@@ -6,7 +6,10 @@ import (
 	"gorm.io/gorm"
 )
 
-// WeeklySnapshot stores historical KPI data for week-over-week comparison
+// WeeklySnapshot stores historical KPI data for week-over-week comparison.
+// Every save of a week creates a new version of its rows; only the latest
+// version (or the one restored last) is current and used for comparisons.
+// (indicator, year, month, week, version) is unique; see database.SnapshotVersionIndex.
 type WeeklySnapshot struct {
 	gorm.Model
 	IndicatorID      string    `gorm:"size:50;not null;index" json:"indicator_id"`
@@ -17,8 +20,16 @@ type WeeklySnapshot struct {
 	Percentage       float64   `gorm:"type:decimal(5,2)" json:"percentage"`
 	SnapshotDate     time.Time `gorm:"not null;index" json:"snapshot_date"`
 	Month            int       `gorm:"not null;index" json:"month"` // 1-12
-	WeekNumber       int       `gorm:"not null" json:"week_number"`
+	WeekNumber       int       `gorm:"not null" json:"week_number"` // Week of month (1-6), derived from SnapshotDate
 	Year             int       `gorm:"not null;index" json:"year"`
+	ISOYear          int       `gorm:"not null;default:0;index:idx_weekly_snapshots_iso_week" json:"iso_year"`
+	ISOWeek          int       `gorm:"not null;default:0;index:idx_weekly_snapshots_iso_week" json:"iso_week"`
+	Version          int       `gorm:"not null;default:1" json:"version"`             // 1, 2, ... per week
+	IsCurrent        bool      `gorm:"not null;default:true;index" json:"is_current"` // Always inserted as true; older versions are flipped with Update
+	SavedBy          string    `gorm:"size:100;not null;default:''" json:"saved_by"`
+	RestoredFrom     int       `gorm:"not null;default:0" json:"restored_from"` // Version this one was restored from, 0 if none
+	IsManual         bool      `gorm:"not null;default:false" json:"is_manual"` // Values entered or corrected by hand
+	ManualReason     string    `gorm:"size:255;not null;default:''" json:"manual_reason"`
 }
 
 // TableName specifies the table name for WeeklySnapshot model
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// YearSpreadsheet maps a calendar year to the Google spreadsheet and sheet holding
+// that year's KPI data, so several years can be read side by side
+type YearSpreadsheet struct {
+	gorm.Model
+	Year          int    `gorm:"not null;uniqueIndex" json:"year"`
+	SpreadsheetID string `gorm:"size:255;not null" json:"spreadsheet_id"`
+	SheetName     string `gorm:"size:100;not null" json:"sheet_name"`
+}
+
+// TableName specifies the table name for YearSpreadsheet model
+func (YearSpreadsheet) TableName() string {
+	return "year_spreadsheets"
+}
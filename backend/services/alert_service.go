@@ -0,0 +1,379 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+// AlertStatuses are the statuses a status_transition rule can name
+var AlertStatuses = []string{"supergreen", "green", "yellow", "red"}
+
+// AlertService evaluates the alert rules against the newest dashboard data, opening an alert
+// when a rule's condition starts to hold for an indicator and resolving it once it no longer does
+type AlertService struct {
+	webhooks *WebhookService
+	mu       sync.Mutex // Serialises evaluations so an alert is opened only once
+}
+
+// NewAlertService creates a new AlertService instance
+func NewAlertService(webhooks *WebhookService) *AlertService {
+	return &AlertService{
+		webhooks: webhooks,
+	}
+}
+
+// AlertEvent is the data of an alert.firing or alert.resolved event
+type AlertEvent struct {
+	Alert models.Alert     `json:"alert"`
+	Rule  models.AlertRule `json:"rule"`
+}
+
+// EvaluateDashboard evaluates the rules against computed dashboard data. Only the running month
+// is evaluated, since browsing an earlier month must not fire or resolve today's alerts.
+// baseline holds the previous week's snapshots the data was compared against.
+func (s *AlertService) EvaluateDashboard(data *DashboardResponse, baseline []models.WeeklySnapshot) {
+	now := time.Now()
+	if data.Period.Month != int(now.Month()) || data.Period.Year != now.Year() || len(data.Indicators) == 0 {
+		return
+	}
+	s.evaluate(data.Indicators, NewSnapshotPeriod(data.Period.Month, data.Period.Year, now), baseline)
+}
+
+// EvaluateSnapshot evaluates the rules against a saved snapshot, unless a later week has already
+// been saved (a backfill of an old week does not describe the current state)
+func (s *AlertService) EvaluateSnapshot(indicators []IndicatorResponse, period SnapshotPeriod) {
+	var later int64
+	database.DB.Model(&models.WeeklySnapshot{}).
+		Where("is_current = ? AND snapshot_date > ?", true, period.Date).
+		Where("NOT (month = ? AND year = ? AND week_number = ?)", period.Month, period.Year, period.WeekNumber).
+		Count(&later)
+	if later > 0 {
+		return
+	}
+
+	_, baseline := snapshotsBefore(period.WeekStart())
+	s.evaluate(indicators, period, baseline)
+}
+
+// ResolveRule resolves the firing alerts of a rule, e.g. when it is deactivated or deleted
+func (s *AlertService) ResolveRule(rule *models.AlertRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firing []models.Alert
+	if err := database.DB.Where("rule_id = ? AND state = ?", rule.ID, models.AlertFiring).Find(&firing).Error; err != nil {
+		log.Printf("Warning: Failed to load alerts of rule %d: %v", rule.ID, err)
+		return
+	}
+	now := time.Now()
+	for i := range firing {
+		s.resolve(&firing[i], rule, now)
+	}
+}
+
+// ListAlerts returns a page of alerts, firing ones first and then newest first
+func ListAlerts(state string, ruleID uint, indicatorCode string, page, pageSize int) ([]models.Alert, int64, error) {
+	query := database.DB.Model(&models.Alert{})
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+	if ruleID != 0 {
+		query = query.Where("rule_id = ?", ruleID)
+	}
+	if indicatorCode != "" {
+		query = query.Where("indicator_code = ?", indicatorCode)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var alerts []models.Alert
+	err := query.Order("CASE WHEN state = 'firing' THEN 0 ELSE 1 END").
+		Order("fired_at desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&alerts).Error
+	return alerts, total, err
+}
+
+// evaluate checks every active rule against every indicator it watches
+func (s *AlertService) evaluate(indicators []IndicatorResponse, period SnapshotPeriod, baseline []models.WeeklySnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rules []models.AlertRule
+	if err := database.DB.Where("is_active = ?", true).Order("id").Find(&rules).Error; err != nil {
+		log.Printf("Warning: Failed to load alert rules: %v", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	var firing []models.Alert
+	if err := database.DB.Where("state = ?", models.AlertFiring).Find(&firing).Error; err != nil {
+		log.Printf("Warning: Failed to load firing alerts: %v", err)
+		return
+	}
+	open := make(map[string]*models.Alert, len(firing))
+	for i := range firing {
+		open[alertKey(firing[i].RuleID, firing[i].IndicatorCode)] = &firing[i]
+	}
+
+	previous := make(map[string]float64, len(baseline))
+	for _, snap := range baseline {
+		previous[snap.IndicatorID] = snap.Percentage
+	}
+
+	check := &alertCheck{
+		period:     period,
+		thresholds: LoadThresholds(),
+		previous:   previous,
+		history:    make(map[string][]string),
+	}
+	for _, rule := range rules {
+		if rule.Condition == models.AlertConsecutiveRed && rule.Weeks-1 > check.maxHistory {
+			check.maxHistory = rule.Weeks - 1
+		}
+	}
+	now := time.Now()
+
+	for i := range rules {
+		rule := &rules[i]
+		for _, ind := range indicators {
+			if !rule.Applies(ind.Code, ind.Department) {
+				continue
+			}
+
+			alert := open[alertKey(rule.ID, ind.Code)]
+			holds, value, message := check.evaluate(rule, ind, alert != nil)
+
+			switch {
+			case holds && alert == nil:
+				s.fire(rule, ind, period, value, message, now)
+			case holds:
+				alert.Value = value
+				alert.Message = message
+				alert.Month, alert.Year, alert.Week = period.Month, period.Year, period.WeekNumber
+				alert.LastEvaluatedAt = now
+				if err := database.DB.Save(alert).Error; err != nil {
+					log.Printf("Warning: Failed to update alert %d: %v", alert.ID, err)
+				}
+			case alert != nil:
+				s.resolve(alert, rule, now)
+			}
+		}
+	}
+}
+
+// fire opens an alert for a rule and indicator
+func (s *AlertService) fire(rule *models.AlertRule, ind IndicatorResponse, period SnapshotPeriod, value float64, message string, now time.Time) {
+	alert := models.Alert{
+		RuleID:          rule.ID,
+		IndicatorCode:   ind.Code,
+		Department:      ind.Department,
+		IndicatorName:   ind.Name,
+		State:           models.AlertFiring,
+		Message:         message,
+		Value:           value,
+		Month:           period.Month,
+		Year:            period.Year,
+		Week:            period.WeekNumber,
+		FiredAt:         now,
+		LastEvaluatedAt: now,
+	}
+	if err := database.DB.Create(&alert).Error; err != nil {
+		// Another instance opened the same alert first
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			log.Printf("Warning: Failed to open alert for rule %d, indicator %s: %v", rule.ID, ind.Code, err)
+		}
+		return
+	}
+
+	log.Printf("Alert %d firing: %s - %s: %s", alert.ID, rule.Name, ind.Code, message)
+	s.webhooks.Emit(models.WebhookEventAlertFiring, AlertEvent{Alert: alert, Rule: *rule})
+}
+
+// resolve closes a firing alert
+func (s *AlertService) resolve(alert *models.Alert, rule *models.AlertRule, now time.Time) {
+	alert.State = models.AlertResolved
+	alert.ResolvedAt = &now
+	alert.LastEvaluatedAt = now
+	if err := database.DB.Save(alert).Error; err != nil {
+		log.Printf("Warning: Failed to resolve alert %d: %v", alert.ID, err)
+		return
+	}
+
+	log.Printf("Alert %d resolved: %s - %s", alert.ID, rule.Name, alert.IndicatorCode)
+	s.webhooks.Emit(models.WebhookEventAlertResolved, AlertEvent{Alert: *alert, Rule: *rule})
+}
+
+// alertKey identifies the open alert of a rule and indicator
+func alertKey(ruleID uint, code string) string {
+	return fmt.Sprintf("%d/%s", ruleID, code)
+}
+
+// alertCheck evaluates rule conditions for one week of data
+type alertCheck struct {
+	period     SnapshotPeriod
+	thresholds *Thresholds
+	previous   map[string]float64  // Previous week's percentage by indicator code
+	history    map[string][]string // Statuses of earlier weeks by indicator code, newest first
+	maxHistory int                 // Weeks of history the consecutive_red rules need
+}
+
+// evaluate reports whether a rule's condition holds for an indicator, with the value that
+// breached it and a description. A status transition fires on the change itself and then
+// holds for as long as the indicator keeps the new status.
+func (c *alertCheck) evaluate(rule *models.AlertRule, ind IndicatorResponse, firing bool) (bool, float64, string) {
+	switch rule.Condition {
+	case models.AlertStatusTransition:
+		if ind.Status != rule.ToStatus {
+			return false, ind.Percentage, ""
+		}
+		message := fmt.Sprintf("Status is %s at %.1f%%", ind.Status, ind.Percentage)
+		if firing {
+			return true, ind.Percentage, message
+		}
+		prev, ok := c.previous[ind.Code]
+		if !ok {
+			return false, ind.Percentage, ""
+		}
+		prevStatus := c.thresholds.Status(ind.Code, prev, ind.IsInverse)
+		if prevStatus == rule.ToStatus || (rule.FromStatus != "" && prevStatus != rule.FromStatus) {
+			return false, ind.Percentage, ""
+		}
+		return true, ind.Percentage, fmt.Sprintf("Status changed from %s to %s (%.1f%% to %.1f%%)", prevStatus, ind.Status, prev, ind.Percentage)
+
+	case models.AlertPercentageBelow:
+		return ind.Percentage < rule.Threshold, ind.Percentage,
+			fmt.Sprintf("Percentage %.1f%% is below %.1f%%", ind.Percentage, rule.Threshold)
+
+	case models.AlertVarianceBelow:
+		return ind.Variance < rule.Threshold, ind.Variance,
+			fmt.Sprintf("Schedule variance %.1f%% is below %.1f%%", ind.Variance, rule.Threshold)
+
+	case models.AlertWoWDrop:
+		prev, ok := c.previous[ind.Code]
+		if !ok {
+			return false, 0, ""
+		}
+		// For inverse metrics a rise is the drop in performance
+		change := ind.Percentage - prev
+		if ind.IsInverse {
+			change = -change
+		}
+		return -change > rule.Threshold, change,
+			fmt.Sprintf("Worsened by %.1f points since the previous week (%.1f%% to %.1f%%)", -change, prev, ind.Percentage)
+
+	case models.AlertConsecutiveRed:
+		if ind.Status != "red" {
+			return false, 0, ""
+		}
+		streak := 1
+		for _, status := range c.statusHistory(ind, rule.Weeks-1) {
+			if status != "red" {
+				break
+			}
+			streak++
+		}
+		return streak >= rule.Weeks, float64(streak),
+			fmt.Sprintf("Red for %d consecutive weeks", streak)
+	}
+	return false, 0, ""
+}
+
+// statusHistory returns the indicator's status in up to n snapshot weeks before the evaluated
+// week, newest first. The longest lookback of all rules is loaded once per indicator.
+func (c *alertCheck) statusHistory(ind IndicatorResponse, n int) []string {
+	statuses, ok := c.history[ind.Code]
+	if !ok {
+		statuses = c.loadHistory(ind)
+		c.history[ind.Code] = statuses
+	}
+	if len(statuses) > n {
+		return statuses[:n]
+	}
+	return statuses
+}
+
+// loadHistory loads the indicator's status in the last maxHistory snapshot weeks
+func (c *alertCheck) loadHistory(ind IndicatorResponse) []string {
+	var snapshots []models.WeeklySnapshot
+	if err := database.DB.Where("indicator_id = ? AND is_current = ? AND snapshot_date < ?", ind.Code, true, c.period.WeekStart()).
+		Order("snapshot_date desc").
+		Limit(c.maxHistory).
+		Find(&snapshots).Error; err != nil {
+		log.Printf("Warning: Failed to load snapshot history of %s: %v", ind.Code, err)
+	}
+
+	statuses := make([]string, 0, len(snapshots))
+	for _, snap := range snapshots {
+		statuses = append(statuses, c.thresholds.Status(ind.Code, snap.Percentage, ind.IsInverse))
+	}
+	return statuses
+}
+
+// ValidateAlertRule checks a rule's condition parameters and scope
+func ValidateAlertRule(rule *models.AlertRule) error {
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if !models.IsValidAlertCondition(rule.Condition) {
+		return fmt.Errorf("invalid condition: %s", rule.Condition)
+	}
+
+	switch rule.Condition {
+	case models.AlertStatusTransition:
+		if !isAlertStatus(rule.ToStatus) {
+			return errors.New("to_status must be one of supergreen, green, yellow or red")
+		}
+		if rule.FromStatus != "" && (!isAlertStatus(rule.FromStatus) || rule.FromStatus == rule.ToStatus) {
+			return errors.New("from_status must be empty or a different status")
+		}
+	case models.AlertWoWDrop:
+		if rule.Threshold <= 0 {
+			return errors.New("threshold must be greater than 0 percentage points")
+		}
+	case models.AlertConsecutiveRed:
+		if rule.Weeks < 2 || rule.Weeks > 52 {
+			return errors.New("weeks must be between 2 and 52")
+		}
+	}
+
+	if rule.IndicatorCode != "" {
+		var count int64
+		database.DB.Model(&models.Indicator{}).Where("code = ?", rule.IndicatorCode).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("unknown indicator: %s", rule.IndicatorCode)
+		}
+	}
+	if rule.Department != "" {
+		var count int64
+		database.DB.Model(&models.Indicator{}).Where("department = ?", rule.Department).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("unknown department: %s", rule.Department)
+		}
+	}
+	return nil
+}
+
+// isAlertStatus checks if a status can be named by a rule
+func isAlertStatus(status string) bool {
+	for _, s := range AlertStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
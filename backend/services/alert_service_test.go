@@ -0,0 +1,144 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+)
+
+// defaultThresholds returns the built-in bands without reading the database
+func defaultThresholds() *Thresholds {
+	t := &Thresholds{Indicators: make(map[string]models.StatusThreshold)}
+	for _, def := range models.GetDefaultThresholds() {
+		t.setGlobal(def)
+	}
+	return t
+}
+
+func TestAlertCheckEvaluate(t *testing.T) {
+	transition := func(from, to string) *models.AlertRule {
+		return &models.AlertRule{Condition: models.AlertStatusTransition, FromStatus: from, ToStatus: to}
+	}
+	threshold := func(condition string, value float64) *models.AlertRule {
+		return &models.AlertRule{Condition: condition, Threshold: value}
+	}
+	redFor := &models.AlertRule{Condition: models.AlertConsecutiveRed, Weeks: 3}
+	ind := func(pct float64, status string) IndicatorResponse {
+		return IndicatorResponse{Code: "IND", Percentage: pct, Status: status}
+	}
+	inverse := func(pct float64, status string) IndicatorResponse {
+		i := ind(pct, status)
+		i.IsInverse = true
+		return i
+	}
+
+	tests := []struct {
+		name      string
+		rule      *models.AlertRule
+		ind       IndicatorResponse
+		firing    bool
+		previous  map[string]float64
+		history   []string
+		wantHolds bool
+		wantValue float64
+	}{
+		{"turned red", transition("", "red"), ind(40, "red"), false, map[string]float64{"IND": 60}, nil, true, 40},
+		{"red without a previous week", transition("", "red"), ind(40, "red"), false, nil, nil, false, 40},
+		{"already red last week", transition("", "red"), ind(40, "red"), false, map[string]float64{"IND": 50}, nil, false, 40},
+		{"firing and still red", transition("", "red"), ind(30, "red"), true, map[string]float64{"IND": 40}, nil, true, 30},
+		{"firing and recovered", transition("", "red"), ind(70, "yellow"), true, map[string]float64{"IND": 40}, nil, false, 70},
+		{"from status does not match", transition("green", "red"), ind(40, "red"), false, map[string]float64{"IND": 60}, nil, false, 40},
+		{"from status matches", transition("yellow", "red"), ind(40, "red"), false, map[string]float64{"IND": 60}, nil, true, 40},
+		{"inverse turned red", transition("", "red"), inverse(120, "red"), false, map[string]float64{"IND": 90}, nil, true, 120},
+		{"percentage below", threshold(models.AlertPercentageBelow, 80), ind(70, "yellow"), false, nil, nil, true, 70},
+		{"percentage at threshold", threshold(models.AlertPercentageBelow, 80), ind(80, "yellow"), false, nil, nil, false, 80},
+		{"week-over-week drop", threshold(models.AlertWoWDrop, 5), ind(80, "yellow"), false, map[string]float64{"IND": 90}, nil, true, -10},
+		{"small drop", threshold(models.AlertWoWDrop, 5), ind(88, "green"), false, map[string]float64{"IND": 90}, nil, false, -2},
+		{"inverse rise is a drop", threshold(models.AlertWoWDrop, 5), inverse(60, "green"), false, map[string]float64{"IND": 50}, nil, true, -10},
+		{"drop without a previous week", threshold(models.AlertWoWDrop, 5), ind(10, "red"), false, nil, nil, false, 0},
+		{"red for three weeks", redFor, ind(40, "red"), false, nil, []string{"red", "red"}, true, 3},
+		{"streak broken", redFor, ind(40, "red"), false, nil, []string{"red", "yellow", "red"}, false, 2},
+		{"too little history", redFor, ind(40, "red"), false, nil, []string{"red"}, false, 2},
+		{"not red now", redFor, ind(70, "yellow"), true, nil, []string{"red", "red"}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &alertCheck{
+				thresholds: defaultThresholds(),
+				previous:   tt.previous,
+				history:    map[string][]string{"IND": tt.history},
+			}
+			holds, value, message := check.evaluate(tt.rule, tt.ind, tt.firing)
+			if holds != tt.wantHolds || value != tt.wantValue {
+				t.Errorf("evaluate() = %v, %v, want %v, %v", holds, value, tt.wantHolds, tt.wantValue)
+			}
+			if holds && message == "" {
+				t.Error("a holding condition has no message")
+			}
+		})
+	}
+}
+
+func TestAlertCheckVarianceBelow(t *testing.T) {
+	rule := &models.AlertRule{Condition: models.AlertVarianceBelow, Threshold: -10}
+	check := &alertCheck{thresholds: defaultThresholds()}
+
+	for _, tt := range []struct {
+		variance float64
+		want     bool
+	}{{-15, true}, {-10, false}, {5, false}} {
+		if holds, _, _ := check.evaluate(rule, IndicatorResponse{Code: "IND", Variance: tt.variance}, false); holds != tt.want {
+			t.Errorf("variance %v: holds = %v, want %v", tt.variance, holds, tt.want)
+		}
+	}
+}
+
+func TestAlertServiceFiresAndResolves(t *testing.T) {
+	openTestDB(t, "alerts", "alert_rules", "weekly_snapshots", "status_thresholds", "webhook_subscriptions")
+
+	rule := models.AlertRule{Name: "Below 80", Condition: models.AlertPercentageBelow, Threshold: 80, IsActive: true}
+	if err := database.DB.Create(&rule).Error; err != nil {
+		t.Fatalf("failed to create rule: %v", err)
+	}
+
+	service := NewAlertService(NewWebhookService())
+	period := NewSnapshotPeriod(3, 2026, time.Date(2026, 3, 18, 12, 0, 0, 0, time.Local))
+	alerts := func() []models.Alert {
+		var all []models.Alert
+		database.DB.Order("id").Find(&all)
+		return all
+	}
+
+	steps := []struct {
+		name       string
+		percentage float64
+		wantAlerts int    // Alerts of the rule, firing and resolved
+		wantState  string // State of the newest alert
+		wantValue  float64
+	}{
+		{"breach opens an alert", 70, 1, models.AlertFiring, 70},
+		{"repeated evaluation updates it in place", 72, 1, models.AlertFiring, 72},
+		{"recovery resolves it", 90, 1, models.AlertResolved, 72},
+		{"evaluating a recovered indicator again changes nothing", 95, 1, models.AlertResolved, 72},
+		{"a new breach opens a new alert", 60, 2, models.AlertFiring, 60},
+	}
+
+	for _, step := range steps {
+		service.evaluate([]IndicatorResponse{{Code: "IND", Department: "FIN", Name: "Indicator", Percentage: step.percentage}}, period, nil)
+
+		all := alerts()
+		if len(all) != step.wantAlerts {
+			t.Fatalf("%s: %d alerts, want %d", step.name, len(all), step.wantAlerts)
+		}
+		newest := all[len(all)-1]
+		if newest.State != step.wantState || newest.Value != step.wantValue {
+			t.Errorf("%s: newest alert is %s at %v, want %s at %v", step.name, newest.State, newest.Value, step.wantState, step.wantValue)
+		}
+		if (newest.ResolvedAt != nil) != (newest.State == models.AlertResolved) {
+			t.Errorf("%s: resolved_at = %v for a %s alert", step.name, newest.ResolvedAt, newest.State)
+		}
+	}
+}
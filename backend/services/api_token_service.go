@@ -0,0 +1,85 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/middleware"
+	"weekly-dashboard/models"
+)
+
+// API token lifetime bounds, in days
+const (
+	DefaultAPITokenDays = 90
+	MaxAPITokenDays     = 365
+)
+
+// ErrAPITokenNotFound is returned when a token does not exist or belongs to another user
+var ErrAPITokenNotFound = errors.New("API token not found")
+
+// APITokenService manages personal API tokens
+type APITokenService struct{}
+
+// NewAPITokenService creates a new APITokenService instance
+func NewAPITokenService() *APITokenService {
+	return &APITokenService{}
+}
+
+// CreateToken issues a new API token for the user and returns it with its raw value,
+// which is not stored and cannot be shown again
+func (s *APITokenService) CreateToken(user *models.User, name, scope string, expiresInDays int) (*models.APIToken, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	raw := models.APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	token := models.APIToken{
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    raw[:len(models.APITokenPrefix)+6],
+		TokenHash: middleware.HashAPIToken(raw),
+		Scope:     scope,
+		ExpiresAt: time.Now().AddDate(0, 0, expiresInDays),
+	}
+	if err := database.DB.Create(&token).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to store API token: %w", err)
+	}
+	return &token, raw, nil
+}
+
+// ListTokens returns the user's API tokens, newest first
+func (s *APITokenService) ListTokens(userID uint) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken revokes one of the user's API tokens. Revoking an already revoked token is a no-op.
+func (s *APITokenService) RevokeToken(userID, tokenID uint) (*models.APIToken, error) {
+	var token models.APIToken
+	result := database.DB.Where("id = ? AND user_id = ?", tokenID, userID).Limit(1).Find(&token)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to look up API token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAPITokenNotFound
+	}
+	if token.RevokedAt != nil {
+		return &token, nil
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(&token).Update("revoked_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	token.RevokedAt = &now
+	return &token, nil
+}
@@ -2,11 +2,18 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"weekly-dashboard/config"
@@ -19,6 +26,42 @@ import (
 	"golang.org/x/oauth2/google"
 )
 
+// googleRevokeURL is Google's OAuth 2.0 token revocation endpoint
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// Refresh token errors
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used")
+)
+
+// Login rejection reasons, passed to the frontend login page as ?error=<reason>
+const (
+	LoginReasonDenied     = config.LoginReasonDenied
+	LoginReasonDomain     = config.LoginReasonDomain
+	LoginReasonNotAllowed = config.LoginReasonNotAllowed
+	LoginReasonUnverified = config.LoginReasonUnverified
+)
+
+// LoginRejectedError is returned by CreateOrUpdateUser when the account may not sign in
+type LoginRejectedError struct {
+	Email  string
+	Reason string
+}
+
+func (e *LoginRejectedError) Error() string {
+	return fmt.Sprintf("login rejected for %s: %s", e.Email, e.Reason)
+}
+
+// CheckLoginAllowed applies the configured deny list, allow list and allowed domains
+// (see config.LoginRejection), returning a LoginRejectedError if the email may not sign in
+func CheckLoginAllowed(email string, verified bool) error {
+	if reason := config.LoginRejection(email, verified); reason != "" {
+		return &LoginRejectedError{Email: email, Reason: reason}
+	}
+	return nil
+}
+
 // GoogleUserInfo represents user info from Google API
 type GoogleUserInfo struct {
 	ID            string `json:"id"`
@@ -88,7 +131,12 @@ func (s *AuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*Go
 }
 
 // CreateOrUpdateUser creates or updates a user in the database
+// Accounts rejected by CheckLoginAllowed are neither created nor updated.
 func (s *AuthService) CreateOrUpdateUser(userInfo *GoogleUserInfo, token *oauth2.Token) (*models.User, error) {
+	if err := CheckLoginAllowed(userInfo.Email, userInfo.VerifiedEmail); err != nil {
+		return nil, err
+	}
+
 	var user models.User
 
 	result := database.DB.Where("email = ?", userInfo.Email).First(&user)
@@ -103,6 +151,10 @@ func (s *AuthService) CreateOrUpdateUser(userInfo *GoogleUserInfo, token *oauth2
 			RefreshToken: token.RefreshToken,
 			TokenExpiry:  token.Expiry,
 			LastLogin:    time.Now(),
+			Role:         models.RoleViewer,
+		}
+		if config.IsAdminEmail(user.Email) {
+			user.Role = models.RoleAdmin
 		}
 		if err := database.DB.Create(&user).Error; err != nil {
 			return nil, fmt.Errorf("failed to create user: %w", err)
@@ -118,6 +170,10 @@ func (s *AuthService) CreateOrUpdateUser(userInfo *GoogleUserInfo, token *oauth2
 		}
 		user.TokenExpiry = token.Expiry
 		user.LastLogin = time.Now()
+		if config.IsAdminEmail(user.Email) && user.Role != models.RoleAdmin {
+			log.Printf("Granting admin role to bootstrap admin %s", user.Email)
+			user.Role = models.RoleAdmin
+		}
 
 		if err := database.DB.Save(&user).Error; err != nil {
 			return nil, fmt.Errorf("failed to update user: %w", err)
@@ -128,14 +184,21 @@ func (s *AuthService) CreateOrUpdateUser(userInfo *GoogleUserInfo, token *oauth2
 	return &user, nil
 }
 
-// GenerateJWT generates a JWT token for the user
-func (s *AuthService) GenerateJWT(user *models.User) (string, error) {
-	expirationTime := time.Now().Add(time.Duration(config.AppConfig.JWTExpiration) * time.Hour)
+// GenerateJWT generates a short-lived access token for the user's session
+func (s *AuthService) GenerateJWT(user *models.User, sessionID string) (string, error) {
+	expirationTime := time.Now().Add(config.AppConfig.AccessTokenTTL)
+
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
 
 	claims := &middleware.Claims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "weekly-dashboard",
@@ -151,6 +214,218 @@ func (s *AuthService) GenerateJWT(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
+// newTokenID returns a random JWT ID (jti)
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// StartSession begins a new refresh-token family for a login and returns its first
+// refresh token (to be stored in the client's cookie) and the family ID
+func (s *AuthService) StartSession(user *models.User, ipAddress string) (string, string, error) {
+	familyID, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+
+	// Expired chains are no longer needed for reuse detection
+	if err := database.DB.Where("expires_at < ?", time.Now()).Delete(&models.RefreshToken{}).Error; err != nil {
+		log.Printf("Warning: Failed to purge expired refresh tokens: %v", err)
+	}
+
+	raw, err := issueRefreshToken(user.ID, familyID, time.Now().Add(config.AppConfig.RefreshTokenTTL), ipAddress)
+	if err != nil {
+		return "", "", err
+	}
+	return raw, familyID, nil
+}
+
+// RotateRefreshToken consumes a refresh token and issues the next one in its family.
+// Presenting an already-consumed token is treated as theft: the whole family is revoked
+// and ErrRefreshTokenReused is returned. The family keeps the expiry of the original login.
+func (s *AuthService) RotateRefreshToken(raw, ipAddress string) (*models.User, string, string, error) {
+	var current models.RefreshToken
+	result := database.DB.Where("token_hash = ?", hashRefreshToken(raw)).Limit(1).Find(&current)
+	if result.Error != nil {
+		return nil, "", "", fmt.Errorf("failed to look up refresh token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 || current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return nil, "", "", ErrRefreshTokenInvalid
+	}
+
+	// Mark the token used only if nobody else has; losing that race means reuse
+	now := time.Now()
+	update := database.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+		Update("used_at", now)
+	if update.Error != nil {
+		return nil, "", "", fmt.Errorf("failed to rotate refresh token: %w", update.Error)
+	}
+	if update.RowsAffected == 0 {
+		log.Printf("Warning: Refresh token reuse detected for user %d, revoking session family %s", current.UserID, current.FamilyID)
+		if err := s.RevokeSession(current.FamilyID); err != nil {
+			log.Printf("Failed to revoke session family %s: %v", current.FamilyID, err)
+		}
+		return nil, "", "", ErrRefreshTokenReused
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, current.UserID).Error; err != nil {
+		return nil, "", "", ErrRefreshTokenInvalid
+	}
+	if user.SessionsRevokedAt != nil && current.CreatedAt.Before(*user.SessionsRevokedAt) {
+		return nil, "", "", ErrRefreshTokenInvalid
+	}
+	if err := CheckLoginAllowed(user.Email, true); err != nil {
+		s.RevokeSession(current.FamilyID)
+		return nil, "", "", err
+	}
+
+	next, err := issueRefreshToken(user.ID, current.FamilyID, current.ExpiresAt, ipAddress)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return &user, next, current.FamilyID, nil
+}
+
+// RevokeSession revokes every refresh token of a session family
+func (s *AuthService) RevokeSession(familyID string) error {
+	if familyID == "" {
+		return nil
+	}
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RefreshTokenFamily returns the session family of a refresh token, or "" if unknown
+func (s *AuthService) RefreshTokenFamily(raw string) string {
+	var token models.RefreshToken
+	database.DB.Select("family_id").Where("token_hash = ?", hashRefreshToken(raw)).Limit(1).Find(&token)
+	return token.FamilyID
+}
+
+// issueRefreshToken stores a new refresh token of a family and returns its raw value
+func issueRefreshToken(userID uint, familyID string, expiresAt time.Time, ipAddress string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	raw := base64.RawURLEncoding.EncodeToString(b)
+
+	token := models.RefreshToken{
+		TokenHash: hashRefreshToken(raw),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt,
+		IPAddress: ipAddress,
+	}
+	if err := database.DB.Create(&token).Error; err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return raw, nil
+}
+
+// hashRefreshToken returns the hex SHA-256 of a raw refresh token; only hashes are stored
+func hashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// RevokeJWT blocks the token identified by claims until it expires
+func (s *AuthService) RevokeJWT(claims *middleware.Claims) error {
+	expiresAt := time.Now().Add(config.AppConfig.AccessTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	revoked := models.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: expiresAt,
+		RevokedAt: time.Now(),
+	}
+	if err := database.DB.Where("jti = ?", claims.ID).FirstOrCreate(&revoked).Error; err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	// Revocations are only needed until the tokens expire
+	if err := database.DB.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error; err != nil {
+		log.Printf("Warning: Failed to purge expired token revocations: %v", err)
+	}
+	return nil
+}
+
+// RevokeAllSessions invalidates every access, refresh and personal API token issued to the user so far
+func (s *AuthService) RevokeAllSessions(user *models.User) error {
+	now := time.Now()
+	if err := database.DB.Model(user).Update("sessions_revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := database.DB.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke API tokens: %w", err)
+	}
+	user.SessionsRevokedAt = &now
+	return nil
+}
+
+// RevokeGoogleToken revokes the user's stored Google refresh token and forgets the OAuth tokens.
+// The snapshot service user keeps its token so scheduled snapshots keep working.
+func (s *AuthService) RevokeGoogleToken(ctx context.Context, user *models.User) error {
+	if user.RefreshToken == "" && user.AccessToken == "" {
+		return nil
+	}
+	if strings.EqualFold(user.Email, config.AppConfig.SnapshotServiceUser) {
+		log.Printf("Keeping Google token of snapshot service user %s", user.Email)
+		return nil
+	}
+
+	// Revoking the refresh token also invalidates access tokens issued from it
+	token := user.RefreshToken
+	if token == "" {
+		token = user.AccessToken
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleRevokeURL,
+		strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build revoke request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke Google token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 400 means the token was already invalid, which is fine for a logout
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("google token revocation failed: %s", string(body))
+	}
+
+	result := database.DB.Model(user).Updates(map[string]interface{}{
+		"access_token":  "",
+		"refresh_token": "",
+		"token_expiry":  time.Time{},
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear stored Google token: %w", result.Error)
+	}
+	log.Printf("Revoked Google token of user %s", user.Email)
+	return nil
+}
+
 // GetOAuthConfig returns the OAuth config for creating clients
 func (s *AuthService) GetOAuthConfig() *oauth2.Config {
 	return s.oauthConfig
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// Content types of exported files
+const (
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// IsValidExportFormat checks if the export format is supported
+func IsValidExportFormat(format string) bool {
+	return format == ExportFormatCSV || format == ExportFormatXLSX
+}
+
+// ExportContentType returns the content type of an export format
+func ExportContentType(format string) string {
+	if format == ExportFormatXLSX {
+		return ContentTypeXLSX
+	}
+	return ContentTypeCSV
+}
+
+// Excel number formats for exported cells. Percentages are written as fractions so that
+// spreadsheets can keep calculating with them.
+const (
+	xlsxNumberFormat  = "#,##0.00"
+	xlsxPercentFormat = "0.0%"
+	xlsxChangeFormat  = "+0.0%;-0.0%;0.0%"
+)
+
+var dashboardExportHeaders = []string{
+	"Code", "Department", "Indicator", "Target", "Performance", "Percentage (%)", "Status",
+	"WoW Change (pp)", "WoW Direction", "Expected Progress", "Variance (%)", "Schedule Status",
+}
+
+// ExportDashboard renders the dashboard indicators as a CSV or XLSX file
+func ExportDashboard(data *DashboardResponse, format string) ([]byte, error) {
+	if format == ExportFormatXLSX {
+		return exportDashboardXLSX(data)
+	}
+
+	rows := [][]string{dashboardExportHeaders}
+	for _, ind := range data.Indicators {
+		rows = append(rows, []string{
+			ind.Code,
+			ind.Department,
+			ind.Name,
+			formatExportNumber(ind.Target),
+			formatExportNumber(ind.Performance),
+			formatExportNumber(ind.Percentage),
+			ind.Status,
+			formatExportNumber(ind.WowChange),
+			ind.WowDirection,
+			formatExportNumber(ind.ExpectedProgress),
+			formatExportNumber(ind.Variance),
+			ind.ScheduleStatus,
+		})
+	}
+	return writeCSV(rows)
+}
+
+// exportDashboardXLSX writes the dashboard indicators to a single worksheet
+func exportDashboardXLSX(data *DashboardResponse) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := fmt.Sprintf("%s %d", data.Period.MonthName, data.Period.Year)
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+
+	styles, err := newExportStyles(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeXLSXHeader(f, sheet, dashboardExportHeaders, styles.header); err != nil {
+		return nil, err
+	}
+
+	for i, ind := range data.Indicators {
+		row := i + 2
+		values := []interface{}{
+			ind.Code,
+			ind.Department,
+			ind.Name,
+			ind.Target,
+			ind.Performance,
+			ind.Percentage / 100,
+			ind.Status,
+			ind.WowChange / 100,
+			ind.WowDirection,
+			ind.ExpectedProgress,
+			ind.Variance / 100,
+			ind.ScheduleStatus,
+		}
+		if err := setXLSXRow(f, sheet, row, values); err != nil {
+			return nil, err
+		}
+	}
+
+	if last := len(data.Indicators) + 1; last > 1 {
+		columnStyles := map[string]int{
+			"D": styles.number, "E": styles.number, "F": styles.percent,
+			"H": styles.change, "J": styles.number, "K": styles.change,
+		}
+		for col, style := range columnStyles {
+			if err := f.SetCellStyle(sheet, col+"2", fmt.Sprintf("%s%d", col, last), style); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_ = f.SetColWidth(sheet, "A", "B", 14)
+	_ = f.SetColWidth(sheet, "C", "C", 40)
+	_ = f.SetColWidth(sheet, "D", "L", 16)
+	_ = f.SetPanes(sheet, frozenHeaderPanes())
+
+	return writeXLSX(f)
+}
+
+// ExportMonthlySnapshots renders the week-by-indicator percentage matrix of a month as a CSV or XLSX file
+func ExportMonthlySnapshots(data *MonthlySnapshotsResponse, format string) ([]byte, error) {
+	headers := []string{"Code", "Department", "Indicator"}
+	for _, week := range data.AvailableWeeks {
+		headers = append(headers, fmt.Sprintf("Week %d (%%)", week))
+	}
+
+	if format == ExportFormatXLSX {
+		return exportMonthlySnapshotsXLSX(data, headers)
+	}
+
+	rows := [][]string{headers}
+	for _, ind := range data.Indicators {
+		weeks := snapshotWeekMap(ind)
+		row := []string{ind.Code, ind.Department, ind.Name}
+		for _, week := range data.AvailableWeeks {
+			value := ""
+			if w, ok := weeks[week]; ok {
+				value = formatExportNumber(w.Percentage)
+			}
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+	return writeCSV(rows)
+}
+
+// exportMonthlySnapshotsXLSX writes the monthly matrix to a worksheet, leaving missing weeks blank
+// and attaching the reason of manually corrected values as a cell comment
+func exportMonthlySnapshotsXLSX(data *MonthlySnapshotsResponse, headers []string) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := fmt.Sprintf("%s %d", data.MonthName, data.Year)
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+
+	styles, err := newExportStyles(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeXLSXHeader(f, sheet, headers, styles.header); err != nil {
+		return nil, err
+	}
+
+	for i, ind := range data.Indicators {
+		row := i + 2
+		if err := setXLSXRow(f, sheet, row, []interface{}{ind.Code, ind.Department, ind.Name}); err != nil {
+			return nil, err
+		}
+
+		weeks := snapshotWeekMap(ind)
+		for j, week := range data.AvailableWeeks {
+			w, ok := weeks[week]
+			if !ok {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(j+4, row)
+			if err != nil {
+				return nil, err
+			}
+			if err := f.SetCellValue(sheet, cell, w.Percentage/100); err != nil {
+				return nil, err
+			}
+			if err := f.SetCellStyle(sheet, cell, cell, styles.percent); err != nil {
+				return nil, err
+			}
+			if w.Manual && w.ManualReason != "" {
+				_ = f.AddComment(sheet, excelize.Comment{
+					Cell:      cell,
+					Author:    "Dashboard",
+					Paragraph: []excelize.RichTextRun{{Text: "Manual value: " + w.ManualReason}},
+				})
+			}
+		}
+	}
+
+	_ = f.SetColWidth(sheet, "A", "B", 14)
+	_ = f.SetColWidth(sheet, "C", "C", 40)
+	if len(data.AvailableWeeks) > 0 {
+		lastCol, _ := excelize.ColumnNumberToName(len(data.AvailableWeeks) + 3)
+		_ = f.SetColWidth(sheet, "D", lastCol, 14)
+	}
+	_ = f.SetPanes(sheet, frozenHeaderPanes())
+
+	return writeXLSX(f)
+}
+
+// exportStyles holds the style IDs shared by the exported worksheets
+type exportStyles struct {
+	header  int
+	number  int
+	percent int
+	change  int
+}
+
+// newExportStyles registers the header and number format styles in the workbook
+func newExportStyles(f *excelize.File) (*exportStyles, error) {
+	header, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	formats := []string{xlsxNumberFormat, xlsxPercentFormat, xlsxChangeFormat}
+	ids := make([]int, len(formats))
+	for i, format := range formats {
+		numFmt := format
+		if ids[i], err = f.NewStyle(&excelize.Style{CustomNumFmt: &numFmt}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &exportStyles{header: header, number: ids[0], percent: ids[1], change: ids[2]}, nil
+}
+
+// writeXLSXHeader writes the bold header row of a worksheet
+func writeXLSXHeader(f *excelize.File, sheet string, headers []string, style int) error {
+	values := make([]interface{}, len(headers))
+	for i, h := range headers {
+		values[i] = h
+	}
+	if err := setXLSXRow(f, sheet, 1, values); err != nil {
+		return err
+	}
+	lastCol, err := excelize.ColumnNumberToName(len(headers))
+	if err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheet, "A1", lastCol+"1", style)
+}
+
+// setXLSXRow writes values into a worksheet row starting at column A
+func setXLSXRow(f *excelize.File, sheet string, row int, values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, row)
+	if err != nil {
+		return err
+	}
+	return f.SetSheetRow(sheet, cell, &values)
+}
+
+// frozenHeaderPanes keeps the header row visible while scrolling
+func frozenHeaderPanes() *excelize.Panes {
+	return &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}
+}
+
+// writeXLSX serialises a workbook
+func writeXLSX(f *excelize.File) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write xlsx: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCSV serialises rows as CSV
+func writeCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatExportNumber formats a value with two decimals for CSV output
+func formatExportNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// snapshotWeekMap indexes an indicator's snapshot weeks by week number
+func snapshotWeekMap(ind IndicatorSnapshots) map[int]SnapshotWeekData {
+	weeks := make(map[int]SnapshotWeekData, len(ind.Weeks))
+	for _, w := range ind.Weeks {
+		weeks[w.Week] = w
+	}
+	return weeks
+}
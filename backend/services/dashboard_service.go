@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -11,17 +12,23 @@ import (
 	"weekly-dashboard/config"
 	"weekly-dashboard/database"
 	"weekly-dashboard/models"
+
+	"gorm.io/gorm"
 )
 
 // DashboardService handles dashboard business logic
 type DashboardService struct {
-	sheetsService *SheetsService
+	source   KPISource
+	webhooks *WebhookService
+	alerts   *AlertService
 }
 
 // NewDashboardService creates a new DashboardService instance
-func NewDashboardService(sheetsService *SheetsService) *DashboardService {
+func NewDashboardService(source KPISource, webhooks *WebhookService, alerts *AlertService) *DashboardService {
 	return &DashboardService{
-		sheetsService: sheetsService,
+		source:   source,
+		webhooks: webhooks,
+		alerts:   alerts,
 	}
 }
 
@@ -53,9 +60,20 @@ type OverallPerformance struct {
 
 // WeeklyTrend represents week-over-week trend
 type WeeklyTrend struct {
-	Change           float64 `json:"change"`
-	Direction        string  `json:"direction"`
-	GreenCountChange int     `json:"green_count_change"`
+	Change           float64       `json:"change"`
+	Direction        string        `json:"direction"`
+	GreenCountChange int           `json:"green_count_change"`
+	ComparedWith     *ComparedWeek `json:"compared_with"` // nil when there is no earlier snapshot
+}
+
+// ComparedWeek identifies the snapshot week a week-over-week comparison was made against
+type ComparedWeek struct {
+	Month        int       `json:"month"`
+	Year         int       `json:"year"`
+	WeekNumber   int       `json:"week_number"`
+	ISOYear      int       `json:"iso_year"`
+	ISOWeek      int       `json:"iso_week"`
+	SnapshotDate time.Time `json:"snapshot_date"`
 }
 
 // Period represents the selected period
@@ -79,6 +97,8 @@ type DashboardResponse struct {
 	WeeklyTrend        WeeklyTrend         `json:"weekly_trend"`
 	ScheduleSummary    ScheduleSummary     `json:"schedule_summary"`
 	Indicators         []IndicatorResponse `json:"indicators"`
+	Scoring            *Scoring            `json:"scoring"`
+	DataSource         FetchInfo           `json:"data_source"` // Live or cached KPI values and their age
 	LastUpdated        time.Time           `json:"last_updated"`
 }
 
@@ -99,7 +119,9 @@ type MonthsResponse struct {
 	} `json:"current_month"`
 }
 
-// GetDashboardData fetches and calculates dashboard data
+// GetDashboardData fetches and calculates dashboard data and evaluates the alert rules against
+// it, whoever computes it (dashboard views, exports, digests, scheduled snapshots). Repeated
+// evaluations are idempotent: a firing alert is updated in place, never opened twice.
 func (s *DashboardService) GetDashboardData(ctx context.Context, user *models.User, month, year int) (*DashboardResponse, error) {
 	// Get all active indicators
 	var indicators []models.Indicator
@@ -107,24 +129,43 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, user *models.Us
 		return nil, err
 	}
 
-	// Only fetch from spreadsheet if year matches configured spreadsheet year
-	var kpiDataList []KPIData
-	if year == config.AppConfig.SpreadsheetYear {
-		var err error
-		kpiDataList, err = s.sheetsService.FetchKPIData(ctx, user, indicators, month)
-		if err != nil {
-			log.Printf("Warning: Error fetching sheet data: %v", err)
-			// Continue with empty data
+	// Fetch from the source for the requested year; years without data yield an empty dashboard
+	kpiDataList, fetchInfo, err := fetchKPIDataWithInfo(ctx, s.source, user, indicators, month, year)
+	if err != nil {
+		if errors.Is(err, ErrNoSpreadsheetForYear) {
+			log.Printf("Skipping spreadsheet fetch: %v", err)
+		} else {
+			log.Printf("Warning: Error fetching KPI data: %v", err)
 		}
-	} else {
-		log.Printf("Skipping spreadsheet fetch: requested year %d != configured year %d", year, config.AppConfig.SpreadsheetYear)
+		// Continue with empty data
 	}
 
-	// Get previous week's data for WoW comparison
-	prevSnapshots := s.getPreviousWeekSnapshots(month, year)
+	// Get previous week's data for WoW comparison (may lie in an earlier month or year)
+	comparedWeek, prevWeek := s.getPreviousWeekSnapshots(month, year)
+
+	response := s.buildDashboard(indicators, kpiDataList, month, year, time.Now(), comparedWeek, prevWeek)
+	response.DataSource = fetchInfo
+
+	s.alerts.EvaluateDashboard(response, prevWeek)
+
+	return response, nil
+}
+
+// buildDashboard grades KPI values and compares them with the previous week's snapshots.
+// asOf is the day the schedule variance is measured at.
+func (s *DashboardService) buildDashboard(indicators []models.Indicator, kpiDataList []KPIData, month, year int, asOf time.Time, comparedWeek *ComparedWeek, prevWeek []models.WeeklySnapshot) *DashboardResponse {
+	prevSnapshots := make(map[string]float64, len(prevWeek))
+	for _, snap := range prevWeek {
+		prevSnapshots[snap.IndicatorID] = snap.Percentage
+	}
+
+	// Load status bands (global defaults plus per-indicator overrides) and score weights
+	thresholds := LoadThresholds()
+	scoring := LoadScoring(indicators)
 
 	// Build indicator responses
 	var indicatorResponses []IndicatorResponse
+	var scoreItems []scoreItem
 	greenCount, yellowCount, redCount := 0, 0, 0
 
 	for _, kpiData := range kpiDataList {
@@ -133,19 +174,9 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, user *models.Us
 		// are reversed in calculateStatus, but the percentage calculation is the same.
 		// e.g. Non Billable Cost: actual 8M / target max 10M = 80% (good, under budget)
 		// e.g. Turn Over: actual 3 / target max 5 = 60% (good, low turnover)
-		var calculatedPercentage float64
-		if kpiData.Target != 0 {
-			calculatedPercentage = (kpiData.Performance / kpiData.Target) * 100
-		} else {
-			calculatedPercentage = 0
-		}
+		calculatedPercentage := calculatePercentage(kpiData.Target, kpiData.Performance)
 
-		// Apply capping logic — cap at 999% to avoid display issues
-		if calculatedPercentage > 999 {
-			calculatedPercentage = 999
-		}
-
-		status := calculateStatus(calculatedPercentage, kpiData.IsInverse)
+		status := thresholds.Status(kpiData.IndicatorCode, calculatedPercentage, kpiData.IsInverse)
 
 		switch status {
 		case "green", "supergreen":
@@ -156,11 +187,19 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, user *models.Us
 			redCount++
 		}
 
+		scoreItems = append(scoreItems, scoreItem{
+			Code:       kpiData.IndicatorCode,
+			Department: kpiData.Department,
+			Percentage: calculatedPercentage,
+			Status:     status,
+			IsInverse:  kpiData.IsInverse,
+		})
+
 		// Calculate WoW change
 		wowChange, wowDirection := s.calculateWoWChange(kpiData.IndicatorCode, calculatedPercentage, prevSnapshots)
 
 		// Calculate expected progress and variance
-		expectedProgress, variance, scheduleStatus := calculateVariance(kpiData.Target, kpiData.Performance, kpiData.IsInverse, month, year)
+		expectedProgress, variance, scheduleStatus := calculateVariance(kpiData.Target, kpiData.Performance, kpiData.IsInverse, month, year, asOf)
 
 		indicatorResponses = append(indicatorResponses, IndicatorResponse{
 			Code:             kpiData.IndicatorCode,
@@ -179,22 +218,13 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, user *models.Us
 		})
 	}
 
-	// Calculate overall performance
-	totalIndicators := greenCount + yellowCount + redCount
-	overallPercentage := 0.0
-	if totalIndicators > 0 {
-		overallPercentage = (float64(greenCount) / float64(totalIndicators)) * 100
-	}
+	// Calculate overall performance with the configured score method
+	overallPercentage := scoring.OverallPercentage(scoreItems)
 
-	overallStatus := "red"
-	if overallPercentage > 85 {
-		overallStatus = "green"
-	} else if overallPercentage > 55 {
-		overallStatus = "yellow"
-	}
+	overallStatus := thresholds.OverallStatus(overallPercentage)
 
 	// Calculate weekly trend (difference between current and previous overall percentage)
-	weeklyChange, weeklyDirection, prevGreenCount := s.calculateWeeklyTrend(month, year, overallPercentage)
+	weeklyChange, weeklyDirection, prevGreenCount := s.calculateWeeklyTrend(prevWeek, overallPercentage, thresholds, scoring)
 	greenCountChange := greenCount - prevGreenCount
 
 	// Calculate schedule summary
@@ -227,6 +257,7 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, user *models.Us
 			Change:           weeklyChange,
 			Direction:        weeklyDirection,
 			GreenCountChange: greenCountChange,
+			ComparedWith:     comparedWeek,
 		},
 		ScheduleSummary: ScheduleSummary{
 			AheadCount:      aheadCount,
@@ -234,35 +265,48 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, user *models.Us
 			BehindCount:     behindCount,
 		},
 		Indicators:  indicatorResponses,
+		Scoring:     scoring,
 		LastUpdated: time.Now(),
 	}
-
-	return response, nil
+	return response
 }
 
 // GetAvailableMonths returns list of available months for the dashboard
 func (s *DashboardService) GetAvailableMonths() *MonthsResponse {
 	now := time.Now()
-	currentMonth := int(now.Month())
-	spreadsheetYear := config.AppConfig.SpreadsheetYear
+	years := s.source.Years()
+	if len(years) == 0 {
+		years = []int{config.AppConfig.SpreadsheetYear}
+	}
 
-	// Generate months for the configured spreadsheet year
+	// Generate months for every year the source has data for
 	var months []MonthOption
 
-	for m := 1; m <= 12; m++ {
-		months = append(months, MonthOption{
-			Month:   m,
-			Year:    spreadsheetYear,
-			Label:   getMonthName(m) + " " + strconv.Itoa(spreadsheetYear),
-			HasData: s.hasDataForMonth(m, spreadsheetYear),
-		})
+	for _, year := range years {
+		for m := 1; m <= 12; m++ {
+			months = append(months, MonthOption{
+				Month:   m,
+				Year:    year,
+				Label:   getMonthName(m) + " " + strconv.Itoa(year),
+				HasData: s.hasDataForMonth(m, year),
+			})
+		}
 	}
 
-	// Determine current month: if we're in the spreadsheet year, use actual month
-	// Otherwise default to January
+	// Determine current month: if the current year is available, use the actual month
+	// Otherwise default to January of the configured spreadsheet year (or the latest year)
 	displayMonth := 1
-	if now.Year() == spreadsheetYear {
-		displayMonth = currentMonth
+	displayYear := years[len(years)-1]
+	for _, year := range years {
+		if year == config.AppConfig.SpreadsheetYear {
+			displayYear = year
+		}
+	}
+	for _, year := range years {
+		if year == now.Year() {
+			displayMonth = int(now.Month())
+			displayYear = year
+		}
 	}
 
 	return &MonthsResponse{
@@ -272,30 +316,54 @@ func (s *DashboardService) GetAvailableMonths() *MonthsResponse {
 			Year  int `json:"year"`
 		}{
 			Month: displayMonth,
-			Year:  spreadsheetYear,
+			Year:  displayYear,
 		},
 	}
 }
 
-// getPreviousWeekSnapshots gets snapshots from previous week
-func (s *DashboardService) getPreviousWeekSnapshots(month, year int) map[string]float64 {
-	snapshots := make(map[string]float64)
+// getPreviousWeekSnapshots finds the nearest snapshot week before the week the dashboard
+// represents and returns it with one snapshot per indicator. The search crosses month and
+// year boundaries, so week 1 of January compares against the last snapshot of December.
+// Returns nil if no earlier snapshot exists.
+func (s *DashboardService) getPreviousWeekSnapshots(month, year int) (*ComparedWeek, []models.WeeklySnapshot) {
+	current := NewSnapshotPeriod(month, year, referenceDate(month, year))
+	return snapshotsBefore(current.WeekStart())
+}
+
+// snapshotsBefore returns the current snapshot rows of the latest week saved before weekStart
+func snapshotsBefore(weekStart time.Time) (*ComparedWeek, []models.WeeklySnapshot) {
+	var latest models.WeeklySnapshot
+	result := database.DB.Where("snapshot_date < ? AND is_current = ?", weekStart, true).
+		Order("snapshot_date desc").
+		Limit(1).
+		Find(&latest)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, nil
+	}
 
 	var records []models.WeeklySnapshot
-	database.DB.Where("month = ? AND year = ?", month, year).
+	database.DB.Where("month = ? AND year = ? AND week_number = ? AND is_current = ?", latest.Month, latest.Year, latest.WeekNumber, true).
 		Order("snapshot_date desc").
 		Find(&records)
 
-	// Get the most recent snapshot for each indicator
+	// Keep the most recent snapshot for each indicator
+	var snapshots []models.WeeklySnapshot
 	seen := make(map[string]bool)
 	for _, record := range records {
 		if !seen[record.IndicatorID] {
-			snapshots[record.IndicatorID] = record.Percentage
+			snapshots = append(snapshots, record)
 			seen[record.IndicatorID] = true
 		}
 	}
 
-	return snapshots
+	return &ComparedWeek{
+		Month:        latest.Month,
+		Year:         latest.Year,
+		WeekNumber:   latest.WeekNumber,
+		ISOYear:      latest.ISOYear,
+		ISOWeek:      latest.ISOWeek,
+		SnapshotDate: latest.SnapshotDate,
+	}, snapshots
 }
 
 // calculateWoWChange calculates week-over-week change
@@ -352,24 +420,8 @@ func (s *DashboardService) calculateWeeklyTrendFromIndicators(indicators []Indic
 	return averageChange, direction
 }
 
-// getPreviousGreenCount gets the green count from the last snapshot
-
-// calculateWeeklyTrend calculates overall weekly trend (legacy, kept for reference)
-func (s *DashboardService) calculateWeeklyTrend(month, year int, currentPercentage float64) (float64, string, int) {
-	// Get last week's overall performance from snapshots
-	var lastSnapshot models.WeeklySnapshot
-	result := database.DB.Where("month = ? AND year = ?", month, year).
-		Order("snapshot_date desc").
-		First(&lastSnapshot)
-
-	if result.Error != nil || result.RowsAffected == 0 {
-		return 0, "neutral", 0
-	}
-
-	// Calculate total green count from that week's snapshots
-	var snapshots []models.WeeklySnapshot
-	database.DB.Where("snapshot_date = ?", lastSnapshot.SnapshotDate).Find(&snapshots)
-
+// calculateWeeklyTrend calculates the overall weekly trend against the previous snapshot week
+func (s *DashboardService) calculateWeeklyTrend(snapshots []models.WeeklySnapshot, currentPercentage float64, thresholds *Thresholds, scoring *Scoring) (float64, string, int) {
 	if len(snapshots) == 0 {
 		return 0, "neutral", 0
 	}
@@ -382,17 +434,25 @@ func (s *DashboardService) calculateWeeklyTrend(month, year int, currentPercenta
 		inverseMap[ind.Code] = ind.IsInverse
 	}
 
-	// Count green indicators from previous snapshot
+	// Count green indicators from previous snapshot and score it like the current data
 	prevGreenCount := 0
+	var prevItems []scoreItem
 	for _, snap := range snapshots {
 		isInverse := inverseMap[snap.IndicatorID]
-		status := calculateStatus(snap.Percentage, isInverse)
+		status := thresholds.Status(snap.IndicatorID, snap.Percentage, isInverse)
 		if status == "green" || status == "supergreen" {
 			prevGreenCount++
 		}
+		prevItems = append(prevItems, scoreItem{
+			Code:       snap.IndicatorID,
+			Department: snap.Department,
+			Percentage: snap.Percentage,
+			Status:     status,
+			IsInverse:  isInverse,
+		})
 	}
 
-	prevOverallPercentage := (float64(prevGreenCount) / float64(len(snapshots))) * 100
+	prevOverallPercentage := scoring.OverallPercentage(prevItems)
 	change := currentPercentage - prevOverallPercentage
 
 	direction := "neutral"
@@ -414,46 +474,63 @@ func (s *DashboardService) hasDataForMonth(month, year int) bool {
 	return count > 0
 }
 
-// calculateStatus determines the status color based on percentage
-// Normal metrics (higher is better): >100% supergreen, 85-100% green, 55-85% yellow, <55% red
+// calculatePercentage returns performance / target * 100, 0 without a target, clamped to
+// ±999% to avoid display issues and to fit the snapshot percentage column (decimal(5,2))
+func calculatePercentage(target, performance float64) float64 {
+	if target == 0 {
+		return 0
+	}
+	percentage := (performance / target) * 100
+	if percentage > 999 {
+		percentage = 999
+	} else if percentage < -999 {
+		percentage = -999
+	}
+	return percentage
+}
+
+// calculateStatus determines the status color based on percentage and the indicator's bands
+// Normal metrics (higher is better), default bands 100/85/55:
+//
+//	>Upper supergreen, >Middle green, >Lower yellow, otherwise red
+//
 // Inverse metrics (lower is better, e.g. Non Billable Cost, Turn Over):
 //
 //	Percentage = actual/target, so >100% means EXCEEDING max target = BAD
-//	<55% supergreen, 55-85% green, 85-100% yellow, >=100% red
-func calculateStatus(percentage float64, isInverse bool) string {
+//	<Lower supergreen, Lower-Middle green, Middle-Upper yellow, >=Upper red
+func calculateStatus(percentage float64, isInverse bool, band models.StatusThreshold) string {
 	if isInverse {
 		// Inverse: lower percentage = better (under max target)
-		if percentage >= 100 {
+		if percentage >= band.Upper {
 			return "red"
-		} else if percentage >= 85 {
+		} else if percentage >= band.Middle {
 			return "yellow"
-		} else if percentage >= 55 {
+		} else if percentage >= band.Lower {
 			return "green"
 		}
 		return "supergreen"
 	}
 
 	// Normal: higher percentage = better
-	if percentage > 100 {
+	if percentage > band.Upper {
 		return "supergreen"
-	} else if percentage > 85 {
+	} else if percentage > band.Middle {
 		return "green"
-	} else if percentage > 55 {
+	} else if percentage > band.Lower {
 		return "yellow"
 	}
 	return "red"
 }
 
 // calculateVariance calculates expected progress, % variance, and schedule status
-// Expected progress is prorated linearly: target × (currentDay / totalDaysInMonth)
-func calculateVariance(target, actual float64, isInverse bool, month, year int) (expectedProgress, variance float64, scheduleStatus string) {
+// Expected progress is prorated linearly: target × (day of asOf / totalDaysInMonth)
+func calculateVariance(target, actual float64, isInverse bool, month, year int, asOf time.Time) (expectedProgress, variance float64, scheduleStatus string) {
 	if target == 0 {
 		return 0, 0, "on_schedule"
 	}
 
 	// Calculate day progress within the month
-	now := time.Now()
-	currentDay := now.Day()
+	currentDay := asOf.Day()
 	totalDays := daysInMonth(month, year)
 
 	// Progress ratio (what fraction of the month has passed)
@@ -511,47 +588,207 @@ func getMonthName(month int) string {
 	return ""
 }
 
-// SaveSnapshot saves a weekly snapshot to the database (upsert - deletes existing week data first)
-func (s *DashboardService) SaveSnapshot(indicators []IndicatorResponse, month, year, weekNumber int) error {
-	snapshotDate := time.Now()
-
-	// Delete existing snapshots for this month/year/week (upsert behavior)
-	deleteResult := database.DB.Where("month = ? AND year = ? AND week_number = ?", month, year, weekNumber).
-		Delete(&models.WeeklySnapshot{})
-	if deleteResult.Error != nil {
-		log.Printf("Warning: Failed to delete existing snapshots: %v", deleteResult.Error)
-	} else if deleteResult.RowsAffected > 0 {
-		log.Printf("Deleted %d existing snapshots for month=%d, year=%d, week=%d", deleteResult.RowsAffected, month, year, weekNumber)
-	}
-
+// SaveSnapshot saves the indicators as a new version of the period's week and makes it
+// the current one. Earlier versions are kept. Returns the new version number.
+func (s *DashboardService) SaveSnapshot(indicators []IndicatorResponse, period SnapshotPeriod, savedBy string) (int, error) {
+	rows := make([]models.WeeklySnapshot, 0, len(indicators))
 	for _, indicator := range indicators {
-		snapshot := models.WeeklySnapshot{
+		rows = append(rows, models.WeeklySnapshot{
 			IndicatorID:      indicator.Code,
 			Department:       indicator.Department,
 			IndicatorName:    indicator.Name,
 			TargetValue:      indicator.Target,
 			PerformanceValue: indicator.Performance,
 			Percentage:       indicator.Percentage,
-			SnapshotDate:     snapshotDate,
-			Month:            month,
-			WeekNumber:       weekNumber,
-			Year:             year,
+			SnapshotDate:     period.Date,
+			ISOYear:          period.ISOYear,
+			ISOWeek:          period.ISOWeek,
+			SavedBy:          savedBy,
+		})
+	}
+
+	var replaced []models.WeeklySnapshot
+	version, saved, err := saveSnapshotVersion(period.Month, period.Year, period.WeekNumber, func(current []models.WeeklySnapshot) ([]models.WeeklySnapshot, error) {
+		replaced = current
+		return rows, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Saved %d snapshots as version %d for month %d, week %d, year %d (ISO %d-W%02d)",
+		len(indicators), version, period.Month, period.WeekNumber, period.Year, period.ISOYear, period.ISOWeek)
+
+	s.snapshotSaved(SnapshotSavedEvent{
+		Month:      period.Month,
+		Year:       period.Year,
+		Week:       period.WeekNumber,
+		Version:    version,
+		Source:     "save",
+		SavedBy:    savedBy,
+		Indicators: len(rows),
+	}, saved, replaced)
+	return version, nil
+}
+
+// snapshotSaved runs after every new current version of a week (save, restore or correction):
+// it emits snapshot.saved and kpi.red, evaluates the alert rules and regenerates the week's
+// report from the saved rows. Failures are logged; the saved version stands.
+func (s *DashboardService) snapshotSaved(event SnapshotSavedEvent, saved, replaced []models.WeeklySnapshot) {
+	s.webhooks.Emit(models.WebhookEventSnapshotSaved, event)
+	if len(saved) == 0 {
+		return
+	}
+
+	data, period, err := s.snapshotDashboard(event.Month, event.Year, saved)
+	if err != nil {
+		log.Printf("Warning: Failed to load saved snapshot of month %d, week %d, year %d: %v", event.Month, event.Week, event.Year, err)
+		return
+	}
+
+	s.emitTurnedRed(data.Indicators, period, replaced)
+	s.alerts.EvaluateSnapshot(data.Indicators, period)
+	if _, err := s.storeWeeklyReport(data, event.Week, event.SavedBy); err != nil {
+		log.Printf("Warning: Failed to generate weekly report: %v", err)
+	}
+}
+
+// emitTurnedRed emits kpi.red for the indicators that are red in a saved snapshot but were not
+// in the version it replaced or, for a week saved for the first time, in the previous week
+func (s *DashboardService) emitTurnedRed(indicators []IndicatorResponse, period SnapshotPeriod, replaced []models.WeeklySnapshot) {
+	baseline := replaced
+	if len(baseline) == 0 {
+		_, baseline = snapshotsBefore(period.WeekStart())
+	}
+	previous := make(map[string]float64, len(baseline))
+	for _, snap := range baseline {
+		previous[snap.IndicatorID] = snap.Percentage
+	}
+
+	thresholds := LoadThresholds()
+	for _, ind := range indicators {
+		if ind.Status != "red" {
+			continue
 		}
+		event := KPIRedEvent{
+			Month:       period.Month,
+			Year:        period.Year,
+			Week:        period.WeekNumber,
+			Code:        ind.Code,
+			Department:  ind.Department,
+			Name:        ind.Name,
+			Target:      ind.Target,
+			Performance: ind.Performance,
+			Percentage:  ind.Percentage,
+		}
+		if pct, ok := previous[ind.Code]; ok {
+			event.PreviousStatus = thresholds.Status(ind.Code, pct, ind.IsInverse)
+			if event.PreviousStatus == "red" {
+				continue
+			}
+			event.PreviousPercentage = &pct
+		}
+		s.webhooks.Emit(models.WebhookEventKPIRed, event)
+	}
+}
 
-		if err := database.DB.Create(&snapshot).Error; err != nil {
-			log.Printf("Failed to save snapshot for %s: %v", indicator.Code, err)
-			return err
+// SnapshotSummary summarises the current snapshot of one week, e.g. for the audit log
+type SnapshotSummary struct {
+	Version      int                `json:"version"`
+	SnapshotDate string             `json:"snapshot_date"`
+	Indicators   int                `json:"indicators"`
+	Percentages  map[string]float64 `json:"percentages"` // By indicator code
+}
+
+// GetSnapshotSummary summarises the current snapshot of a week, or returns nil if there is none
+func (s *DashboardService) GetSnapshotSummary(month, year, week int) (*SnapshotSummary, error) {
+	var snapshots []models.WeeklySnapshot
+	if err := database.DB.Where("month = ? AND year = ? AND week_number = ? AND is_current = ?", month, year, week, true).
+		Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+
+	summary := &SnapshotSummary{
+		Version:      snapshots[0].Version,
+		SnapshotDate: snapshots[0].SnapshotDate.Format("2006-01-02"),
+		Indicators:   len(snapshots),
+		Percentages:  make(map[string]float64, len(snapshots)),
+	}
+	for _, snap := range snapshots {
+		summary.Percentages[snap.IndicatorID] = snap.Percentage
+	}
+	return summary, nil
+}
+
+// GetSnapshotDashboard builds the dashboard of a week from its current snapshot rather than the
+// live KPI values, compared with the snapshot week before it. Returns nil if the week has no snapshot.
+func (s *DashboardService) GetSnapshotDashboard(month, year, week int) (*DashboardResponse, error) {
+	var rows []models.WeeklySnapshot
+	if err := database.DB.Where("month = ? AND year = ? AND week_number = ? AND is_current = ?", month, year, week, true).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	data, _, err := s.snapshotDashboard(month, year, rows)
+	return data, err
+}
+
+// snapshotDashboard builds the dashboard of a week's snapshot rows and returns it with the week's period
+func (s *DashboardService) snapshotDashboard(month, year int, rows []models.WeeklySnapshot) (*DashboardResponse, SnapshotPeriod, error) {
+	period := NewSnapshotPeriod(month, year, rows[0].SnapshotDate)
+
+	var indicators []models.Indicator
+	if err := database.DB.Order("display_order").Find(&indicators).Error; err != nil {
+		return nil, period, err
+	}
+	byCode := make(map[string]models.WeeklySnapshot, len(rows))
+	for _, row := range rows {
+		byCode[row.IndicatorID] = row
+	}
+
+	// Snapshot rows in dashboard order; rows of indicators deleted since come last
+	kpiDataList := make([]KPIData, 0, len(rows))
+	appendRow := func(row models.WeeklySnapshot, isInverse bool) {
+		kpiDataList = append(kpiDataList, KPIData{
+			IndicatorCode: row.IndicatorID,
+			Department:    row.Department,
+			Name:          row.IndicatorName,
+			Target:        row.TargetValue,
+			Performance:   row.PerformanceValue,
+			IsInverse:     isInverse,
+		})
+		delete(byCode, row.IndicatorID)
+	}
+	for _, ind := range indicators {
+		if row, ok := byCode[ind.Code]; ok {
+			appendRow(row, ind.IsInverse)
+		}
+	}
+	for _, row := range rows {
+		if _, ok := byCode[row.IndicatorID]; ok {
+			appendRow(row, false)
 		}
 	}
 
-	log.Printf("Saved %d snapshots for month %d, week %d, year %d", len(indicators), month, weekNumber, year)
-	return nil
+	comparedWeek, prevWeek := snapshotsBefore(period.WeekStart())
+
+	response := s.buildDashboard(indicators, kpiDataList, month, year, period.Date, comparedWeek, prevWeek)
+	response.DataSource = FetchInfo{FetchedAt: rows[0].CreatedAt}
+	return response, period, nil
 }
 
 // SnapshotWeekData represents snapshot data for a single week
 type SnapshotWeekData struct {
-	Week       int     `json:"week"`
-	Percentage float64 `json:"percentage"`
+	Week         int     `json:"week"`
+	Percentage   float64 `json:"percentage"`
+	Manual       bool    `json:"manual"`                  // Entered or corrected by hand
+	ManualReason string  `json:"manual_reason,omitempty"` // Why the value was corrected
 }
 
 // IndicatorSnapshots represents an indicator with its weekly snapshot data
@@ -574,7 +811,7 @@ type MonthlySnapshotsResponse struct {
 // GetSnapshotsByMonth returns all snapshots for a month grouped by indicator
 func (s *DashboardService) GetSnapshotsByMonth(month, year int) (*MonthlySnapshotsResponse, error) {
 	var snapshots []models.WeeklySnapshot
-	result := database.DB.Where("month = ? AND year = ? AND week_number >= 1 AND week_number <= 5", month, year).
+	result := database.DB.Where("month = ? AND year = ? AND week_number >= 1 AND week_number <= ? AND is_current = ?", month, year, MaxWeeksInMonth, true).
 		Order("indicator_id, week_number").
 		Find(&snapshots)
 
@@ -607,8 +844,10 @@ func (s *DashboardService) GetSnapshotsByMonth(month, year int) (*MonthlySnapsho
 		if !seenWeeks[key] {
 			seenWeeks[key] = true
 			indicatorMap[snap.IndicatorID].Weeks = append(indicatorMap[snap.IndicatorID].Weeks, SnapshotWeekData{
-				Week:       snap.WeekNumber,
-				Percentage: snap.Percentage,
+				Week:         snap.WeekNumber,
+				Percentage:   snap.Percentage,
+				Manual:       snap.IsManual,
+				ManualReason: snap.ManualReason,
 			})
 		}
 	}
@@ -621,7 +860,7 @@ func (s *DashboardService) GetSnapshotsByMonth(month, year int) (*MonthlySnapsho
 
 	// Build available weeks sorted
 	var availableWeeks []int
-	for w := 1; w <= 5; w++ {
+	for w := 1; w <= MaxWeeksInMonth; w++ {
 		if weekSet[w] {
 			availableWeeks = append(availableWeeks, w)
 		}
@@ -636,26 +875,47 @@ func (s *DashboardService) GetSnapshotsByMonth(month, year int) (*MonthlySnapsho
 	}, nil
 }
 
-// DeleteSnapshotWeek deletes all snapshots and screenshots for a specific week
+// DeleteSnapshotWeek deletes all snapshot versions and the screenshot of a specific week
+// in one transaction, so a failure leaves the week untouched
 func (s *DashboardService) DeleteSnapshotWeek(month, year, week int) error {
-	// Delete weekly snapshots
-	snapResult := database.DB.Where("month = ? AND year = ? AND week_number = ?", month, year, week).
-		Delete(&models.WeeklySnapshot{})
-	if snapResult.Error != nil {
-		log.Printf("Failed to delete snapshots: %v", snapResult.Error)
-		return snapResult.Error
-	}
-	log.Printf("Deleted %d snapshot records for month=%d, year=%d, week=%d", snapResult.RowsAffected, month, year, week)
+	var snapshots, screenshots int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockSnapshotWeek(tx, month, year, week); err != nil {
+			return err
+		}
 
-	// Delete corresponding screenshot
-	screenResult := database.DB.Where("month = ? AND year = ? AND week = ?", month, year, week).
-		Delete(&models.Screenshot{})
-	if screenResult.Error != nil {
-		log.Printf("Warning: Failed to delete screenshot: %v", screenResult.Error)
-		// Don't fail the whole operation if screenshot delete fails
-	} else if screenResult.RowsAffected > 0 {
-		log.Printf("Deleted screenshot for month=%d, year=%d, week=%d", month, year, week)
-	}
+		snapResult := tx.Where("month = ? AND year = ? AND week_number = ?", month, year, week).
+			Delete(&models.WeeklySnapshot{})
+		if snapResult.Error != nil {
+			return fmt.Errorf("failed to delete snapshots: %w", snapResult.Error)
+		}
+		snapshots = snapResult.RowsAffected
 
+		screenResult := tx.Where("month = ? AND year = ? AND week = ?", month, year, week).
+			Delete(&models.Screenshot{})
+		if screenResult.Error != nil {
+			return fmt.Errorf("failed to delete screenshot: %w", screenResult.Error)
+		}
+		screenshots = screenResult.RowsAffected
+
+		if err := tx.Unscoped().Where("month = ? AND year = ? AND week = ?", month, year, week).
+			Delete(&models.WeeklyReport{}).Error; err != nil {
+			return fmt.Errorf("failed to delete report: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to delete snapshot week: %v", err)
+		return err
+	}
+
+	log.Printf("Deleted %d snapshot records and %d screenshots for month=%d, year=%d, week=%d", snapshots, screenshots, month, year, week)
+	s.webhooks.Emit(models.WebhookEventSnapshotDeleted, SnapshotDeletedEvent{
+		Month:       month,
+		Year:        year,
+		Week:        week,
+		Snapshots:   snapshots,
+		Screenshots: screenshots,
+	})
 	return nil
 }
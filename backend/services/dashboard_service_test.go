@@ -0,0 +1,29 @@
+package services
+
+import "testing"
+
+func TestCalculatePercentage(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      float64
+		performance float64
+		want        float64
+	}{
+		{"on target", 200, 200, 100},
+		{"half way", 200, 100, 50},
+		{"inverse under budget", 10, 8, 80},
+		{"zero target", 0, 50, 0},
+		{"negative performance", 100, -25, -25},
+		{"clamped above", 1, 5000, 999},
+		{"clamped below", 1, -5000, -999},
+		{"tiny target", 0.01, 1e13, 999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculatePercentage(tt.target, tt.performance); got != tt.want {
+				t.Errorf("calculatePercentage(%v, %v) = %v, want %v", tt.target, tt.performance, got, tt.want)
+			}
+		})
+	}
+}
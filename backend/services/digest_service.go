@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+// digestMoverCount is how many of the biggest week-over-week movers a digest lists
+const digestMoverCount = 5
+
+// DigestService emails the weekly dashboard digest to the configured recipients,
+// after each snapshot save and/or on its own cron schedule
+type DigestService struct {
+	dashboardService *DashboardService
+	cron             *cron.Cron
+	entryID          cron.EntryID
+	mu               sync.Mutex
+}
+
+// NewDigestService creates a new DigestService instance
+func NewDigestService(dashboardService *DashboardService) *DigestService {
+	return &DigestService{
+		dashboardService: dashboardService,
+		cron:             cron.New(),
+	}
+}
+
+// Digest is the content of one digest email
+type Digest struct {
+	Period       Period
+	Week         int
+	Department   string // Empty for the whole dashboard
+	Overall      OverallPerformance
+	Trend        WeeklyTrend
+	Schedule     ScheduleSummary
+	Red          []IndicatorResponse
+	Movers       []IndicatorResponse // Biggest absolute WoW changes first
+	Behind       []IndicatorResponse
+	DashboardURL string
+}
+
+// DigestResult reports how a digest send went
+type DigestResult struct {
+	Emails     int      `json:"emails"`     // Emails sent, one per recipient
+	Recipients int      `json:"recipients"` // Recipients addressed, sent or not
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// Start schedules the configured digest expression and starts the cron loop
+func (s *DigestService) Start() {
+	if err := s.Reschedule(config.AppConfig.DigestSchedule); err != nil {
+		log.Printf("Warning: digest schedule not started: %v", err)
+	}
+	s.cron.Start()
+}
+
+// Stop stops the cron loop and waits for a running digest to finish
+func (s *DigestService) Stop() {
+	ctx := s.cron.Stop()
+	<-ctx.Done()
+}
+
+// Reschedule replaces the digest schedule. An empty expression disables scheduled digests.
+func (s *DigestService) Reschedule(expr string) error {
+	if err := ValidateSchedule(expr); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entryID != 0 {
+		s.cron.Remove(s.entryID)
+		s.entryID = 0
+	}
+	if expr == "" {
+		return nil
+	}
+
+	id, err := s.cron.AddFunc(expr, s.runScheduled)
+	if err != nil {
+		return fmt.Errorf("failed to schedule digests: %w", err)
+	}
+	s.entryID = id
+	log.Printf("Digest emails scheduled: '%s'", expr)
+	return nil
+}
+
+// runScheduled sends the digest of the current month and week, read as the snapshot service user
+func (s *DigestService) runScheduled() {
+	user, err := loadServiceUser(config.AppConfig.SnapshotServiceUser)
+	if err != nil {
+		log.Printf("Scheduled digest skipped: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotRunTimeout)
+	defer cancel()
+
+	now := time.Now()
+	period := NewSnapshotPeriod(int(now.Month()), now.Year(), now)
+	data, err := s.dashboardService.GetDashboardData(ctx, user, period.Month, period.Year)
+	if err != nil {
+		log.Printf("Scheduled digest failed to fetch dashboard data: %v", err)
+		return
+	}
+
+	result, err := s.Send(data, period.WeekNumber)
+	if err != nil {
+		log.Printf("Scheduled digest failed: %v", err)
+		return
+	}
+	log.Printf("Scheduled digest sent: %d emails to %d recipients", result.Emails, result.Recipients)
+}
+
+// NotifySnapshot sends the digest in the background after a snapshot save, if enabled
+func (s *DigestService) NotifySnapshot(data *DashboardResponse, week int) {
+	if !config.AppConfig.DigestOnSnapshot || !MailConfigured() {
+		return
+	}
+	go func() {
+		result, err := s.Send(data, week)
+		if err != nil {
+			log.Printf("Digest after snapshot failed: %v", err)
+			return
+		}
+		log.Printf("Digest after snapshot sent: %d emails to %d recipients", result.Emails, result.Recipients)
+	}()
+}
+
+// Send emails the digest to every recipient: the digest is built once per department, and each
+// recipient gets their own email so the recipient list is not disclosed.
+// Failures of individual emails are collected in the result rather than stopping the send.
+func (s *DigestService) Send(data *DashboardResponse, week int) (*DigestResult, error) {
+	if !MailConfigured() {
+		return nil, fmt.Errorf("no SMTP server configured")
+	}
+
+	recipients, err := ListDigestRecipients()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load digest recipients: %w", err)
+	}
+
+	byDepartment := make(map[string][]string)
+	departments := []string{}
+	for _, r := range recipients {
+		if _, ok := byDepartment[r.Department]; !ok {
+			departments = append(departments, r.Department)
+		}
+		byDepartment[r.Department] = append(byDepartment[r.Department], r.Email)
+	}
+
+	result := &DigestResult{}
+	for _, dept := range departments {
+		digest := BuildDigest(data, week, dept)
+		html, err := RenderDigest(digest)
+		if err != nil {
+			return result, err
+		}
+		subject := digestSubject(digest)
+		for _, to := range byDepartment[dept] {
+			result.Recipients++
+			if err := SendHTMLMail([]string{to}, subject, html); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s (%s): %v", to, digestAudience(dept), err))
+				continue
+			}
+			result.Emails++
+		}
+	}
+	return result, nil
+}
+
+// BuildDigest selects the red, most changed and behind-schedule indicators of a department
+// (or of the whole dashboard when department is empty)
+func BuildDigest(data *DashboardResponse, week int, department string) *Digest {
+	d := &Digest{
+		Period:       data.Period,
+		Week:         week,
+		Department:   department,
+		Overall:      data.OverallPerformance,
+		Trend:        data.WeeklyTrend,
+		Schedule:     data.ScheduleSummary,
+		DashboardURL: config.AppConfig.FrontendURL,
+	}
+
+	for _, ind := range data.Indicators {
+		if department != "" && !strings.EqualFold(ind.Department, department) {
+			continue
+		}
+		if ind.Status == "red" {
+			d.Red = append(d.Red, ind)
+		}
+		if ind.WowDirection != "neutral" && ind.WowChange != 0 {
+			d.Movers = append(d.Movers, ind)
+		}
+		if ind.ScheduleStatus == "behind" {
+			d.Behind = append(d.Behind, ind)
+		}
+	}
+
+	sort.SliceStable(d.Movers, func(i, j int) bool {
+		return math.Abs(d.Movers[i].WowChange) > math.Abs(d.Movers[j].WowChange)
+	})
+	if len(d.Movers) > digestMoverCount {
+		d.Movers = d.Movers[:digestMoverCount]
+	}
+	return d
+}
+
+// RenderDigest renders a digest as an HTML email
+func RenderDigest(d *Digest) (string, error) {
+	var buf bytes.Buffer
+	if err := digestTemplate.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// digestSubject returns the subject line of a digest email
+func digestSubject(d *Digest) string {
+	subject := fmt.Sprintf("Weekly KPI digest: %s %d week %d", d.Period.MonthName, d.Period.Year, d.Week)
+	if d.Department != "" {
+		subject += " (" + d.Department + ")"
+	}
+	return subject
+}
+
+// digestAudience names a recipient list in logs and errors
+func digestAudience(department string) string {
+	if department == "" {
+		return "all departments"
+	}
+	return department
+}
+
+// ListDigestRecipients returns the digest recipients ordered by department and email
+func ListDigestRecipients() ([]models.DigestRecipient, error) {
+	var recipients []models.DigestRecipient
+	err := database.DB.Order("department ASC, email ASC").Find(&recipients).Error
+	return recipients, err
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// digestStatusColors mirror the dashboard's status colours for email clients, which ignore stylesheets
+var digestStatusColors = map[string]string{
+	"supergreen": "#1b5e20",
+	"green":      "#4caf50",
+	"yellow":     "#ffc107",
+	"red":        "#f44336",
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"pct": func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"signed": func(v float64) string {
+		return fmt.Sprintf("%+.1f", v)
+	},
+	"statusColor": func(status string) string {
+		if c, ok := digestStatusColors[status]; ok {
+			return c
+		}
+		return "#94a3b8"
+	},
+	"arrow": func(direction string) template.HTML {
+		switch direction {
+		case "up":
+			return `<span style="color:#4caf50">&#9650;</span>`
+		case "down":
+			return `<span style="color:#f44336">&#9660;</span>`
+		}
+		return `<span style="color:#94a3b8">&#8212;</span>`
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="margin:0;padding:24px;background:#f1f5f9;font-family:Arial,Helvetica,sans-serif;color:#1e293b">
+<table width="100%" cellpadding="0" cellspacing="0" style="max-width:640px;margin:0 auto;background:#ffffff;border-radius:8px">
+<tr><td style="padding:24px">
+  <h1 style="margin:0 0 4px;font-size:20px">Weekly KPI digest</h1>
+  <p style="margin:0 0 20px;color:#64748b">{{.Period.MonthName}} {{.Period.Year}}, week {{.Week}}{{if .Department}} &middot; {{.Department}}{{end}}</p>
+
+  <table width="100%" cellpadding="8" cellspacing="0" style="border:1px solid #e2e8f0;border-radius:6px;margin-bottom:20px">
+    <tr>
+      <td>
+        <div style="font-size:12px;color:#64748b">Overall performance</div>
+        <div style="font-size:28px;font-weight:bold;color:{{statusColor .Overall.Status}}">{{pct .Overall.Percentage}}</div>
+      </td>
+      <td style="font-size:13px">
+        <span style="color:#4caf50">&#9679;</span> {{.Overall.GreenCount}} green<br>
+        <span style="color:#ffc107">&#9679;</span> {{.Overall.YellowCount}} yellow<br>
+        <span style="color:#f44336">&#9679;</span> {{.Overall.RedCount}} red
+      </td>
+      <td style="font-size:13px">
+        {{arrow .Trend.Direction}} {{signed .Trend.Change}} pp week over week<br>
+        {{.Schedule.BehindCount}} behind schedule
+      </td>
+    </tr>
+  </table>
+
+  <h2 style="font-size:15px;margin:0 0 8px">Red indicators</h2>
+  {{if .Red}}
+  <table width="100%" cellpadding="6" cellspacing="0" style="font-size:13px;margin-bottom:20px">
+    <tr style="background:#f8fafc;text-align:left"><th>Indicator</th><th>Department</th><th style="text-align:right">%</th></tr>
+    {{range .Red}}
+    <tr style="border-top:1px solid #e2e8f0"><td>{{.Name}}</td><td>{{.Department}}</td><td style="text-align:right;color:#f44336;font-weight:bold">{{pct .Percentage}}</td></tr>
+    {{end}}
+  </table>
+  {{else}}<p style="font-size:13px;color:#64748b;margin:0 0 20px">No indicators are red.</p>{{end}}
+
+  <h2 style="font-size:15px;margin:0 0 8px">Biggest week-over-week movers</h2>
+  {{if .Movers}}
+  <table width="100%" cellpadding="6" cellspacing="0" style="font-size:13px;margin-bottom:20px">
+    <tr style="background:#f8fafc;text-align:left"><th>Indicator</th><th>Department</th><th style="text-align:right">%</th><th style="text-align:right">Change (pp)</th></tr>
+    {{range .Movers}}
+    <tr><td>{{.Name}}</td><td>{{.Department}}</td><td style="text-align:right;color:{{statusColor .Status}}">{{pct .Percentage}}</td><td style="text-align:right">{{arrow .WowDirection}} {{signed .WowChange}}</td></tr>
+    {{end}}
+  </table>
+  {{else}}<p style="font-size:13px;color:#64748b;margin:0 0 20px">No week-over-week changes.</p>{{end}}
+
+  <h2 style="font-size:15px;margin:0 0 8px">Behind schedule</h2>
+  {{if .Behind}}
+  <table width="100%" cellpadding="6" cellspacing="0" style="font-size:13px;margin-bottom:20px">
+    <tr style="background:#f8fafc;text-align:left"><th>Indicator</th><th>Department</th><th style="text-align:right">Variance</th></tr>
+    {{range .Behind}}
+    <tr><td>{{.Name}}</td><td>{{.Department}}</td><td style="text-align:right">{{signed .Variance}}%</td></tr>
+    {{end}}
+  </table>
+  {{else}}<p style="font-size:13px;color:#64748b;margin:0 0 20px">All indicators are on or ahead of schedule.</p>{{end}}
+
+  {{if .DashboardURL}}<p style="margin:0"><a href="{{.DashboardURL}}" style="color:#0369a1">Open the dashboard</a></p>{{end}}
+</td></tr>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/models"
+)
+
+// FakeSource is an offline KPI source that generates deterministic values.
+// It is used in CI and demos where no Google account is available.
+type FakeSource struct{}
+
+// NewFakeSource creates a new FakeSource instance
+func NewFakeSource() *FakeSource {
+	return &FakeSource{}
+}
+
+// FetchKPIData generates stable values per indicator, month and year
+func (f *FakeSource) FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("month %d not found in discovered layout", month)
+	}
+
+	var kpiDataList []KPIData
+	for _, indicator := range indicators {
+		if !indicator.IsActive {
+			continue
+		}
+
+		seed := fakeSeed(indicator.Code, month, year)
+		target := float64(50 + seed%451)          // 50..500
+		ratio := 0.4 + float64((seed/451)%81)/100 // 0.40..1.20
+		performance := math.Round(target*ratio*100) / 100
+
+		// Same formula the dashboard grades with: inverse indicators keep actual/target and
+		// only have their status bands reversed, so the value never disagrees with the status
+		percentage := math.Round(calculatePercentage(target, performance)*100) / 100
+
+		kpiDataList = append(kpiDataList, KPIData{
+			IndicatorCode: indicator.Code,
+			Department:    indicator.Department,
+			Name:          indicator.Name,
+			Target:        target,
+			Performance:   performance,
+			Percentage:    percentage,
+			IsInverse:     indicator.IsInverse,
+		})
+	}
+
+	return kpiDataList, nil
+}
+
+// GetLayout returns a synthetic layout mirroring the DashboardTemplate sheet
+func (f *FakeSource) GetLayout(ctx context.Context, user *models.User, year int) (*DiscoveredLayout, error) {
+	monthCols := make(map[int][4]int)
+	for m := 1; m <= 12; m++ {
+		base := 3 + (m-1)*4
+		monthCols[m] = [4]int{base, base + 1, base + 2, base + 3}
+	}
+
+	kpiRows := make(map[string][]int)
+	for _, indicator := range models.GetDefaultIndicators() {
+		key := strings.ToLower(strings.TrimSpace(indicator.SpreadsheetName))
+		kpiRows[key] = append(kpiRows[key], indicator.SpreadsheetRow)
+	}
+
+	return &DiscoveredLayout{
+		MonthColumns: monthCols,
+		KPIRows:      kpiRows,
+		LastRefresh:  time.Now(),
+	}, nil
+}
+
+// TestConnection always succeeds for the fake source
+func (f *FakeSource) TestConnection(ctx context.Context, user *models.User, year int) error {
+	return nil
+}
+
+// Years returns the configured spreadsheet year and the year before it
+func (f *FakeSource) Years() []int {
+	year := config.AppConfig.SpreadsheetYear
+	return []int{year - 1, year}
+}
+
+// InvalidateLayout is a no-op, the fake layout is never cached
+func (f *FakeSource) InvalidateLayout() {}
+
+// fakeSeed hashes an indicator code, month and year into a stable number
+func fakeSeed(code string, month, year int) uint32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s-%d-%d", code, year, month)
+	return h.Sum32()
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/models"
+)
+
+// kpiRefreshTimeout bounds a background refresh of a stale cache entry
+const kpiRefreshTimeout = 30 * time.Second
+
+// maxKPICacheEntries bounds the cached months; the oldest entry is evicted beyond it
+const maxKPICacheEntries = 256
+
+// FetchInfo describes where a dashboard's KPI values came from
+type FetchInfo struct {
+	Cached     bool      `json:"cached"`      // Served from the server-side cache
+	Stale      bool      `json:"stale"`       // Past the TTL; a background refresh was triggered
+	FetchedAt  time.Time `json:"fetched_at"`  // When the values were read from the source
+	AgeSeconds int       `json:"age_seconds"` // Age of the values when served
+}
+
+// kpiInfoSource is a KPISource that also reports where its values came from, like KPICache
+type kpiInfoSource interface {
+	FetchKPIDataWithInfo(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, FetchInfo, error)
+}
+
+// fetchKPIDataWithInfo reads a month from any source, with cache details when the source has them
+func fetchKPIDataWithInfo(ctx context.Context, source KPISource, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, FetchInfo, error) {
+	if src, ok := source.(kpiInfoSource); ok {
+		return src.FetchKPIDataWithInfo(ctx, user, indicators, month, year)
+	}
+	data, err := source.FetchKPIData(ctx, user, indicators, month, year)
+	return data, FetchInfo{FetchedAt: time.Now()}, err
+}
+
+// kpiCacheEntry holds the KPI values of one spreadsheet month
+type kpiCacheEntry struct {
+	data      []KPIData
+	fetchedAt time.Time
+}
+
+// KPICache is a KPISource that caches Google Sheets reads per (spreadsheet, sheet, month).
+// Entries are fresh for config.AppConfig.KPICacheTTL; for a further KPICacheStaleWindow
+// they are still served while a background refresh replaces them. Spreadsheet access
+// checks are cached per user for the TTL. Other sources are read through uncached.
+// Expired entries are pruned on every store and the cache holds at most maxKPICacheEntries.
+type KPICache struct {
+	source KPISource
+
+	mu         sync.Mutex
+	entries    map[string]*kpiCacheEntry
+	refreshing map[string]bool
+	access     map[string]time.Time // user/spreadsheet → last successful access check
+	generation uint64               // Bumped on invalidation; reads started before it are not stored
+}
+
+// NewKPICache creates a KPICache over the given source, usually the SourceRouter
+func NewKPICache(source KPISource) *KPICache {
+	return &KPICache{
+		source:     source,
+		entries:    make(map[string]*kpiCacheEntry),
+		refreshing: make(map[string]bool),
+		access:     make(map[string]time.Time),
+	}
+}
+
+// cacheKey returns the key of a spreadsheet month, or false if the read must not be cached
+func (c *KPICache) cacheKey(year, month int) (string, bool) {
+	if config.AppConfig.KPICacheTTL <= 0 || !c.readsSheets() {
+		return "", false
+	}
+	ref, ok := SpreadsheetForYear(year)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s!%d", ref.key(), month), true
+}
+
+// readsSheets reports whether reads currently go to Google Sheets, the only source worth caching
+func (c *KPICache) readsSheets() bool {
+	switch src := c.source.(type) {
+	case *SourceRouter:
+		return src.ActiveName() == SourceSheets
+	case *SheetsService:
+		return true
+	}
+	return false
+}
+
+// indicatorSignature hashes the indicator fields that shape a read, so edits to
+// indicators never serve values cached for the old definitions
+func indicatorSignature(indicators []models.Indicator) string {
+	h := fnv.New64a()
+	for _, ind := range indicators {
+		fmt.Fprintf(h, "%s|%s|%s|%s|%d|%t|%t;", ind.Code, ind.Department, ind.Name,
+			ind.SpreadsheetName, ind.SpreadsheetRow, ind.IsInverse, ind.IsActive)
+	}
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// FetchKPIData returns the KPI values of a month, from the cache when possible
+func (c *KPICache) FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, error) {
+	data, _, err := c.FetchKPIDataWithInfo(ctx, user, indicators, month, year)
+	return data, err
+}
+
+// FetchKPIDataWithInfo returns the KPI values of a month and whether they were cached
+func (c *KPICache) FetchKPIDataWithInfo(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, FetchInfo, error) {
+	key, ok := c.cacheKey(year, month)
+	if !ok {
+		data, err := c.source.FetchKPIData(ctx, user, indicators, month, year)
+		return data, FetchInfo{FetchedAt: time.Now()}, err
+	}
+	key += "#" + indicatorSignature(indicators)
+
+	ttl := config.AppConfig.KPICacheTTL
+	staleWindow := config.AppConfig.KPICacheStaleWindow
+
+	c.mu.Lock()
+	entry := c.entries[key]
+	c.mu.Unlock()
+
+	if entry != nil {
+		age := time.Since(entry.fetchedAt)
+		if age < ttl {
+			return entry.data, entry.info(true, false), nil
+		}
+		if age < ttl+staleWindow {
+			c.refreshInBackground(key, user, indicators, month, year)
+			return entry.data, entry.info(true, true), nil
+		}
+	}
+
+	generation := c.currentGeneration()
+	data, err := c.source.FetchKPIData(ctx, user, indicators, month, year)
+	if err != nil {
+		// Serve an expired entry rather than nothing when the source is unavailable
+		if entry != nil {
+			log.Printf("Warning: KPI fetch failed, serving cached values from %s: %v", entry.fetchedAt.Format(time.RFC3339), err)
+			return entry.data, entry.info(true, true), nil
+		}
+		return nil, FetchInfo{}, err
+	}
+
+	fetched := c.store(key, data, generation)
+	return data, fetched.info(false, false), nil
+}
+
+// info describes how the entry is being served
+func (e *kpiCacheEntry) info(cached, stale bool) FetchInfo {
+	age := time.Since(e.fetchedAt)
+	return FetchInfo{
+		Cached:     cached,
+		Stale:      stale,
+		FetchedAt:  e.fetchedAt,
+		AgeSeconds: int(age.Seconds()),
+	}
+}
+
+// currentGeneration returns the generation a read is started in
+func (c *KPICache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// store caches fetched values unless the read came back blank or the cache was invalidated
+// while it ran (the values may predate the invalidation)
+func (c *KPICache) store(key string, data []KPIData, generation uint64) *kpiCacheEntry {
+	entry := &kpiCacheEntry{data: data, fetchedAt: time.Now()}
+
+	// A failed batch read returns indicators without values; don't pin that for a whole TTL
+	if !hasKPIValues(data) {
+		return entry
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return entry
+	}
+	c.pruneLocked(entry.fetchedAt)
+	c.entries[key] = entry
+	return entry
+}
+
+// pruneLocked drops entries past the stale window and expired access checks, then evicts the
+// oldest entries until there is room for one more. The caller holds c.mu.
+func (c *KPICache) pruneLocked(now time.Time) {
+	ttl := config.AppConfig.KPICacheTTL
+	maxAge := ttl + config.AppConfig.KPICacheStaleWindow
+	for key, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) >= maxAge {
+			delete(c.entries, key)
+		}
+	}
+	for key, checkedAt := range c.access {
+		if now.Sub(checkedAt) >= ttl {
+			delete(c.access, key)
+		}
+	}
+
+	for len(c.entries) >= maxKPICacheEntries {
+		oldestKey := ""
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if oldestKey == "" || entry.fetchedAt.Before(oldest) {
+				oldestKey, oldest = key, entry.fetchedAt
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+}
+
+// hasKPIValues reports whether any indicator carries a target or performance value
+func hasKPIValues(data []KPIData) bool {
+	for _, kpi := range data {
+		if kpi.Target != 0 || kpi.Performance != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshInBackground re-reads a stale entry once, without blocking the caller
+func (c *KPICache) refreshInBackground(key string, user *models.User, indicators []models.Indicator, month, year int) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	generation := c.generation
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), kpiRefreshTimeout)
+		defer cancel()
+
+		data, err := c.source.FetchKPIData(ctx, user, indicators, month, year)
+		if err != nil {
+			log.Printf("Warning: Background KPI refresh failed for month %d, year %d: %v", month, year, err)
+			return
+		}
+		if generation != c.currentGeneration() {
+			log.Printf("Dropped KPI refresh for month %d, year %d: cache was invalidated meanwhile", month, year)
+			return
+		}
+		c.store(key, data, generation)
+		log.Printf("Refreshed cached KPI data for month %d, year %d", month, year)
+	}()
+}
+
+// GetLayout returns the layout of the active source
+func (c *KPICache) GetLayout(ctx context.Context, user *models.User, year int) (*DiscoveredLayout, error) {
+	return c.source.GetLayout(ctx, user, year)
+}
+
+// TestConnection checks the user's access to the year's data, remembering successes for the TTL
+func (c *KPICache) TestConnection(ctx context.Context, user *models.User, year int) error {
+	key, ok := c.cacheKey(year, 0)
+	if !ok || user == nil {
+		return c.source.TestConnection(ctx, user, year)
+	}
+	key = fmt.Sprintf("%d@%s", user.ID, key)
+
+	c.mu.Lock()
+	checkedAt, found := c.access[key]
+	c.mu.Unlock()
+	if found && time.Since(checkedAt) < config.AppConfig.KPICacheTTL {
+		return nil
+	}
+
+	generation := c.currentGeneration()
+	if err := c.source.TestConnection(ctx, user, year); err != nil {
+		c.mu.Lock()
+		delete(c.access, key)
+		c.mu.Unlock()
+		return err
+	}
+
+	c.mu.Lock()
+	if generation == c.generation {
+		c.access[key] = time.Now()
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// Years returns the years the active source has data for
+func (c *KPICache) Years() []int {
+	return c.source.Years()
+}
+
+// InvalidateLayout drops cached layouts, KPI values and access checks
+func (c *KPICache) InvalidateLayout() {
+	c.source.InvalidateLayout()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[string]*kpiCacheEntry)
+	c.access = make(map[string]time.Time)
+	log.Printf("KPI cache invalidated")
+}
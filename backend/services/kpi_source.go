@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/models"
+)
+
+// Supported KPI data source names (KPI_SOURCE / kpi_source setting)
+const (
+	SourceSheets = "sheets"
+	SourceUpload = "upload"
+	SourceFake   = "fake"
+)
+
+// KPISource is a backend that supplies monthly KPI values to the dashboard.
+// The Google Sheets implementation is the default; other backends (uploaded
+// files, database tables, local fakes) only need to satisfy this interface.
+type KPISource interface {
+	// FetchKPIData returns the values of the given indicators for a month of a year
+	FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, error)
+	// GetLayout returns the discovered month columns and KPI rows of a year's data
+	GetLayout(ctx context.Context, user *models.User, year int) (*DiscoveredLayout, error)
+	// TestConnection checks that the user can read a year's data from the source
+	TestConnection(ctx context.Context, user *models.User, year int) error
+	// Years returns the years the source has data for, oldest first
+	Years() []int
+	// InvalidateLayout drops any cached layout so the next read re-discovers it
+	InvalidateLayout()
+}
+
+// IsValidSourceName reports whether name is a known KPI source
+func IsValidSourceName(name string) bool {
+	switch name {
+	case SourceSheets, SourceUpload, SourceFake:
+		return true
+	}
+	return false
+}
+
+// SourceRouter is a KPISource that delegates to the source selected in
+// config.AppConfig.KPISource, so the active source can be switched at runtime.
+type SourceRouter struct {
+	sources map[string]KPISource
+}
+
+// NewSourceRouter creates a SourceRouter over the Sheets, upload and fake sources
+func NewSourceRouter(sheetsService *SheetsService, uploadSource *UploadSource) *SourceRouter {
+	return &SourceRouter{
+		sources: map[string]KPISource{
+			SourceSheets: sheetsService,
+			SourceUpload: uploadSource,
+			SourceFake:   NewFakeSource(),
+		},
+	}
+}
+
+// Active returns the currently selected source, defaulting to Google Sheets
+func (r *SourceRouter) Active() KPISource {
+	return r.sources[r.ActiveName()]
+}
+
+// ActiveName returns the name of the currently selected source, defaulting to Google Sheets
+func (r *SourceRouter) ActiveName() string {
+	name := config.AppConfig.KPISource
+	if _, ok := r.sources[name]; ok {
+		return name
+	}
+	if name != "" {
+		log.Printf("Warning: unknown KPI source '%s', falling back to Google Sheets", name)
+	}
+	return SourceSheets
+}
+
+// FetchKPIData fetches KPI data from the active source
+func (r *SourceRouter) FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, error) {
+	return r.Active().FetchKPIData(ctx, user, indicators, month, year)
+}
+
+// GetLayout returns the layout of the active source
+func (r *SourceRouter) GetLayout(ctx context.Context, user *models.User, year int) (*DiscoveredLayout, error) {
+	return r.Active().GetLayout(ctx, user, year)
+}
+
+// TestConnection tests access to the active source
+func (r *SourceRouter) TestConnection(ctx context.Context, user *models.User, year int) error {
+	return r.Active().TestConnection(ctx, user, year)
+}
+
+// Years returns the years the active source has data for
+func (r *SourceRouter) Years() []int {
+	return r.Active().Years()
+}
+
+// InvalidateLayout invalidates the cached layout of every source
+func (r *SourceRouter) InvalidateLayout() {
+	for _, source := range r.sources {
+		source.InvalidateLayout()
+	}
+}
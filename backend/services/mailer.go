@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"weekly-dashboard/config"
+)
+
+// SMTP TLS modes
+const (
+	SMTPTLSStartTLS = "starttls"
+	SMTPTLSImplicit = "tls"
+	SMTPTLSNone     = "none"
+)
+
+// smtpTimeout bounds connecting to the SMTP server
+const smtpTimeout = 15 * time.Second
+
+// MailConfigured reports whether an SMTP server is configured
+func MailConfigured() bool {
+	return config.AppConfig.SMTPHost != ""
+}
+
+// SendHTMLMail sends an HTML email through the configured SMTP server
+func SendHTMLMail(to []string, subject, html string) error {
+	cfg := config.AppConfig
+	if cfg.SMTPHost == "" {
+		return fmt.Errorf("no SMTP server configured")
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+
+	msg, err := buildHTMLMessage(cfg.SMTPFrom, to, subject, html)
+	if err != nil {
+		return err
+	}
+
+	client, err := dialSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPTLS)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if cfg.SMTPUsername != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)); err != nil {
+				return fmt.Errorf("smtp auth failed: %w", err)
+			}
+		}
+	}
+
+	if err := client.Mail(cfg.SMTPFrom); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", addr, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dialSMTP connects to the SMTP server using the configured TLS mode
+func dialSMTP(host string, port int, mode string) (*smtp.Client, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	tlsConfig := &tls.Config{ServerName: host}
+
+	var conn net.Conn
+	var err error
+	if mode == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake with %s failed: %w", addr, err)
+	}
+
+	if mode != SMTPTLSImplicit && mode != SMTPTLSNone {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("%s does not support STARTTLS (set SMTP_TLS=none for a local sink)", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("starttls with %s failed: %w", addr, err)
+		}
+	}
+
+	return client, nil
+}
+
+// buildHTMLMessage formats a quoted-printable HTML message with its headers
+func buildHTMLMessage(from string, to []string, subject, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/html; charset=UTF-8",
+		"Content-Transfer-Encoding: quoted-printable",
+	}
+	buf.WriteString(strings.Join(headers, "\r\n"))
+	buf.WriteString("\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(html)); err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
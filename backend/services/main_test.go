@@ -0,0 +1,70 @@
+package services
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+)
+
+// TestMain runs the package tests with the defaults config.Load would use, without reading
+// the environment or a .env file
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{
+		KPISource:           SourceSheets,
+		KPICacheTTL:         5 * time.Minute,
+		KPICacheStaleWindow: 30 * time.Minute,
+		OverallScoreMethod:  "green_ratio",
+		AttainmentCeiling:   100,
+		WeekStartDay:        time.Monday,
+	}
+	os.Exit(m.Run())
+}
+
+var (
+	testDBOnce sync.Once
+	testDBErr  error
+)
+
+// openTestDB connects to and migrates the Postgres database named by TEST_DB_NAME
+// (TEST_DB_HOST, TEST_DB_PORT, TEST_DB_USER and TEST_DB_PASSWORD default to a local server),
+// then empties the given tables. Tests that need a database are skipped without one.
+func openTestDB(t *testing.T, tables ...string) {
+	t.Helper()
+	name := os.Getenv("TEST_DB_NAME")
+	if name == "" {
+		t.Skip("TEST_DB_NAME not set, skipping database test")
+	}
+
+	testDBOnce.Do(func() {
+		config.AppConfig.DBHost = testEnv("TEST_DB_HOST", "localhost")
+		config.AppConfig.DBPort = testEnv("TEST_DB_PORT", "5432")
+		config.AppConfig.DBUser = testEnv("TEST_DB_USER", "postgres")
+		config.AppConfig.DBPassword = testEnv("TEST_DB_PASSWORD", "postgres")
+		config.AppConfig.DBName = name
+		config.AppConfig.DBSSLMode = "disable"
+		if testDBErr = database.Connect(); testDBErr == nil {
+			testDBErr = database.Migrate()
+		}
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+
+	for _, table := range tables {
+		if err := database.DB.Exec("TRUNCATE TABLE " + table + " RESTART IDENTITY CASCADE").Error; err != nil {
+			t.Fatalf("failed to empty %s: %v", table, err)
+		}
+	}
+}
+
+// testEnv returns an environment variable or its default
+func testEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
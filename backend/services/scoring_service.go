@@ -0,0 +1,159 @@
+package services
+
+import (
+	"log"
+	"math"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+)
+
+// Overall score methods (overall_score_method setting)
+const (
+	// ScoreGreenRatio is the share of green indicators, every indicator counting equally
+	ScoreGreenRatio = "green_ratio"
+	// ScoreWeightedGreenRatio is the weighted share of green indicators
+	ScoreWeightedGreenRatio = "weighted_green_ratio"
+	// ScoreWeightedAttainment is the weighted average attainment, each capped at the ceiling
+	ScoreWeightedAttainment = "weighted_attainment"
+)
+
+// IsValidScoreMethod reports whether method is a known overall score method
+func IsValidScoreMethod(method string) bool {
+	switch method {
+	case ScoreGreenRatio, ScoreWeightedGreenRatio, ScoreWeightedAttainment:
+		return true
+	}
+	return false
+}
+
+// IndicatorWeight describes the weight applied to one indicator
+type IndicatorWeight struct {
+	Code             string  `json:"code"`
+	Department       string  `json:"department"`
+	IndicatorWeight  float64 `json:"indicator_weight"`
+	DepartmentWeight float64 `json:"department_weight"`
+	EffectiveWeight  float64 `json:"effective_weight"` // indicator weight × department weight
+}
+
+// Scoring describes how the overall performance percentage was calculated
+type Scoring struct {
+	Method  string            `json:"method"`
+	Ceiling float64           `json:"ceiling"` // Attainment cap for weighted_attainment
+	Weights []IndicatorWeight `json:"weights"`
+
+	indicatorWeights  map[string]float64
+	departmentWeights map[string]float64
+}
+
+// scoreItem is a graded indicator value fed into the overall score
+type scoreItem struct {
+	Code       string
+	Department string
+	Percentage float64
+	Status     string
+	IsInverse  bool
+}
+
+// LoadScoring reads the configured score method and the indicator and department weights
+func LoadScoring(indicators []models.Indicator) *Scoring {
+	scoring := &Scoring{
+		Method:            config.AppConfig.OverallScoreMethod,
+		Ceiling:           config.AppConfig.AttainmentCeiling,
+		indicatorWeights:  make(map[string]float64),
+		departmentWeights: make(map[string]float64),
+	}
+	if !IsValidScoreMethod(scoring.Method) {
+		scoring.Method = ScoreGreenRatio
+	}
+
+	var departmentWeights []models.DepartmentWeight
+	if err := database.DB.Find(&departmentWeights).Error; err != nil {
+		log.Printf("Warning: Failed to load department weights: %v", err)
+	}
+	for _, dw := range departmentWeights {
+		scoring.departmentWeights[dw.Department] = dw.Weight
+	}
+
+	for _, indicator := range indicators {
+		scoring.indicatorWeights[indicator.Code] = indicator.Weight
+		scoring.Weights = append(scoring.Weights, IndicatorWeight{
+			Code:             indicator.Code,
+			Department:       indicator.Department,
+			IndicatorWeight:  indicator.Weight,
+			DepartmentWeight: scoring.departmentWeight(indicator.Department),
+			EffectiveWeight:  scoring.weight(indicator.Code, indicator.Department),
+		})
+	}
+
+	return scoring
+}
+
+// departmentWeight returns the department's weight, 1 if not configured
+func (s *Scoring) departmentWeight(department string) float64 {
+	if w, ok := s.departmentWeights[department]; ok {
+		return w
+	}
+	return 1
+}
+
+// weight returns the effective weight of an indicator, 1 × department weight if unknown
+func (s *Scoring) weight(code, department string) float64 {
+	w, ok := s.indicatorWeights[code]
+	if !ok {
+		w = 1
+	}
+	return w * s.departmentWeight(department)
+}
+
+// attainment converts a percentage into "how well the target was met".
+// Inverse metrics are better when lower, so attainment is target/actual.
+func attainment(percentage float64, isInverse bool) float64 {
+	if !isInverse {
+		return percentage
+	}
+	if percentage <= 0 {
+		return math.Inf(1) // Nothing spent / no turnover: fully attained, capped by the ceiling
+	}
+	return 10000 / percentage
+}
+
+// OverallPercentage calculates the overall performance percentage with the configured method
+func (s *Scoring) OverallPercentage(items []scoreItem) float64 {
+	if len(items) == 0 {
+		return 0
+	}
+
+	var total, totalWeight float64
+	for _, item := range items {
+		isGreen := item.Status == "green" || item.Status == "supergreen"
+
+		switch s.Method {
+		case ScoreWeightedGreenRatio:
+			w := s.weight(item.Code, item.Department)
+			totalWeight += w
+			if isGreen {
+				total += w
+			}
+		case ScoreWeightedAttainment:
+			w := s.weight(item.Code, item.Department)
+			totalWeight += w
+			total += w * math.Min(attainment(item.Percentage, item.IsInverse), s.Ceiling)
+		default:
+			totalWeight++
+			if isGreen {
+				total++
+			}
+		}
+	}
+
+	if totalWeight == 0 {
+		return 0
+	}
+
+	if s.Method == ScoreWeightedAttainment {
+		return total / totalWeight
+	}
+	return (total / totalWeight) * 100
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -29,14 +31,20 @@ type DiscoveredLayout struct {
 // SheetsService handles Google Sheets API operations
 type SheetsService struct {
 	authService *AuthService
-	layout      *DiscoveredLayout
+	layouts     map[string]*DiscoveredLayout // keyed by spreadsheet ID and sheet name
 	layoutMu    sync.RWMutex
+
+	// Service-account client, created from config.AppConfig.GoogleServiceAccountKeyFile on first use
+	saService *sheets.Service
+	saKeyFile string
+	saMu      sync.Mutex
 }
 
 // NewSheetsService creates a new SheetsService instance
 func NewSheetsService(authService *AuthService) *SheetsService {
 	return &SheetsService{
 		authService: authService,
+		layouts:     make(map[string]*DiscoveredLayout),
 	}
 }
 
@@ -127,11 +135,17 @@ func discoverColumns(srv *sheets.Service, spreadsheetID, sheetName string) (map[
 		return nil, fmt.Errorf("header row is empty")
 	}
 
+	return parseHeaderRow(resp.Values[0]), nil
+}
+
+// parseHeaderRow matches header cells against month patterns.
+// Returns map[month][4]int where indices are: [target, lagging, percent, perf] (0-based).
+func parseHeaderRow(header []interface{}) map[int][4]int {
 	result := make(map[int][4]int)
 
-	log.Printf("[Discovery] Header row has %d columns", len(resp.Values[0]))
+	log.Printf("[Discovery] Header row has %d columns", len(header))
 
-	for colIdx, cell := range resp.Values[0] {
+	for colIdx, cell := range header {
 		headerText, ok := cell.(string)
 		if !ok {
 			continue
@@ -157,7 +171,7 @@ func discoverColumns(srv *sheets.Service, spreadsheetID, sheetName string) (map[
 		log.Printf("[Discovery] Col %d (%s) = '%s' → month=%d type=%s", colIdx, indexToCol(colIdx), headerText, month, colType)
 	}
 
-	return result, nil
+	return result
 }
 
 // discoverRows reads column C (index 2, "Leading Indicators") and matches KPI names
@@ -171,14 +185,20 @@ func discoverRows(srv *sheets.Service, spreadsheetID, sheetName string) (map[str
 		return nil, fmt.Errorf("failed to fetch column C: %w", err)
 	}
 
+	return parseKPINames(resp.Values), nil
+}
+
+// parseKPINames indexes the KPI names of a single column (one cell per row).
+// Returns map[lowercase_name][]row_numbers (1-based).
+func parseKPINames(values [][]interface{}) map[string][]int {
 	result := make(map[string][]int)
-	if len(resp.Values) == 0 {
-		return result, nil
+	if len(values) == 0 {
+		return result
 	}
 
-	log.Printf("[Discovery] Column C has %d rows", len(resp.Values))
+	log.Printf("[Discovery] Column C has %d rows", len(values))
 
-	for rowIdx, row := range resp.Values {
+	for rowIdx, row := range values {
 		if len(row) == 0 {
 			continue
 		}
@@ -191,7 +211,7 @@ func discoverRows(srv *sheets.Service, spreadsheetID, sheetName string) (map[str
 		log.Printf("[Discovery] Row %d: '%s'", rowIdx+1, cellStr)
 	}
 
-	return result, nil
+	return result
 }
 
 // DiscoverLayout performs full auto-discovery of spreadsheet layout.
@@ -206,6 +226,11 @@ func DiscoverLayout(srv *sheets.Service, spreadsheetID, sheetName string) (*Disc
 		return nil, fmt.Errorf("row discovery failed: %w", err)
 	}
 
+	return newDiscoveredLayout(monthCols, kpiRows), nil
+}
+
+// newDiscoveredLayout builds a layout from discovered columns and rows and logs the result
+func newDiscoveredLayout(monthCols map[int][4]int, kpiRows map[string][]int) *DiscoveredLayout {
 	layout := &DiscoveredLayout{
 		MonthColumns: monthCols,
 		KPIRows:      kpiRows,
@@ -231,15 +256,26 @@ func DiscoverLayout(srv *sheets.Service, spreadsheetID, sheetName string) (*Disc
 		log.Printf("[Discovery] KPI '%s' → rows %v", name, rows)
 	}
 
-	return layout, nil
+	return layout
+}
+
+// GetLayout returns the layout of the year's spreadsheet
+func (s *SheetsService) GetLayout(ctx context.Context, user *models.User, year int) (*DiscoveredLayout, error) {
+	ref, ok := SpreadsheetForYear(year)
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrNoSpreadsheetForYear, year)
+	}
+	return s.getLayout(ctx, user, ref)
 }
 
-// GetLayout returns the cached layout or triggers discovery if cache is empty/expired.
-func (s *SheetsService) GetLayout(ctx context.Context, user *models.User) (*DiscoveredLayout, error) {
+// getLayout returns the cached layout of a sheet or triggers discovery if cache is empty/expired.
+func (s *SheetsService) getLayout(ctx context.Context, user *models.User, ref SpreadsheetRef) (*DiscoveredLayout, error) {
+	key := ref.key()
+
 	s.layoutMu.RLock()
-	if s.layout != nil && time.Since(s.layout.LastRefresh) < 5*time.Minute {
+	if layout := s.layouts[key]; layout != nil && time.Since(layout.LastRefresh) < 5*time.Minute {
 		defer s.layoutMu.RUnlock()
-		return s.layout, nil
+		return layout, nil
 	}
 	s.layoutMu.RUnlock()
 
@@ -248,38 +284,40 @@ func (s *SheetsService) GetLayout(ctx context.Context, user *models.User) (*Disc
 	defer s.layoutMu.Unlock()
 
 	// Double-check after acquiring write lock
-	if s.layout != nil && time.Since(s.layout.LastRefresh) < 5*time.Minute {
-		return s.layout, nil
+	cached := s.layouts[key]
+	if cached != nil && time.Since(cached.LastRefresh) < 5*time.Minute {
+		return cached, nil
 	}
 
 	srv, err := s.CreateSheetsClient(ctx, user)
 	if err != nil {
 		// If we have a cached layout, return it despite error
-		if s.layout != nil {
+		if cached != nil {
 			log.Printf("Warning: failed to refresh layout, using cached: %v", err)
-			return s.layout, nil
+			return cached, nil
 		}
 		return nil, err
 	}
 
-	layout, err := DiscoverLayout(srv, config.AppConfig.SpreadsheetID, config.AppConfig.SheetName)
+	log.Printf("[Discovery] Discovering layout of %d spreadsheet %s (sheet %s)", ref.Year, ref.SpreadsheetID, ref.SheetName)
+	layout, err := DiscoverLayout(srv, ref.SpreadsheetID, ref.SheetName)
 	if err != nil {
-		if s.layout != nil {
+		if cached != nil {
 			log.Printf("Warning: layout discovery failed, using cached: %v", err)
-			return s.layout, nil
+			return cached, nil
 		}
 		return nil, err
 	}
 
-	s.layout = layout
+	s.layouts[key] = layout
 	return layout, nil
 }
 
-// InvalidateLayout clears the cached layout, forcing re-discovery on next request.
+// InvalidateLayout clears all cached layouts, forcing re-discovery on next request.
 func (s *SheetsService) InvalidateLayout() {
 	s.layoutMu.Lock()
 	defer s.layoutMu.Unlock()
-	s.layout = nil
+	s.layouts = make(map[string]*DiscoveredLayout)
 }
 
 // getIndicatorRow determines the row number for an indicator using discovered layout.
@@ -337,8 +375,66 @@ func indexToCol(index int) string {
 	return result
 }
 
-// CreateSheetsClient creates a new Google Sheets client using user's token
+// CreateSheetsClient creates the Google Sheets client used for reads. It uses the
+// service account when a key file is configured and the user's token otherwise.
+// Access checks must use CreateUserSheetsClient instead.
 func (s *SheetsService) CreateSheetsClient(ctx context.Context, user *models.User) (*sheets.Service, error) {
+	if config.AppConfig.GoogleServiceAccountKeyFile != "" {
+		srv, err := s.serviceAccountClient()
+		if err == nil {
+			return srv, nil
+		}
+		if user == nil {
+			return nil, err
+		}
+		log.Printf("Warning: %v, falling back to token of user %s", err, user.Email)
+	}
+
+	return s.CreateUserSheetsClient(ctx, user)
+}
+
+// serviceAccountClient returns the shared Sheets client authenticated with the service-account key
+func (s *SheetsService) serviceAccountClient() (*sheets.Service, error) {
+	keyFile := config.AppConfig.GoogleServiceAccountKeyFile
+
+	s.saMu.Lock()
+	defer s.saMu.Unlock()
+
+	if s.saService != nil && s.saKeyFile == keyFile {
+		return s.saService, nil
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account key: %w", err)
+	}
+	var key struct {
+		ClientEmail string `json:"client_email"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("invalid service account key: %w", err)
+	}
+
+	// The client outlives any single request, so it is not bound to a request context
+	srv, err := sheets.NewService(context.Background(),
+		option.WithAuthCredentialsJSON(option.ServiceAccount, data),
+		option.WithScopes(sheets.SpreadsheetsReadonlyScope))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account sheets service: %w", err)
+	}
+
+	log.Printf("Using service account %s for Google Sheets reads", key.ClientEmail)
+	s.saService = srv
+	s.saKeyFile = keyFile
+	return srv, nil
+}
+
+// CreateUserSheetsClient creates a new Google Sheets client using user's token
+func (s *SheetsService) CreateUserSheetsClient(ctx context.Context, user *models.User) (*sheets.Service, error) {
+	if user == nil {
+		return nil, fmt.Errorf("no user token available for Google Sheets")
+	}
+
 	// Get refreshed token
 	token, err := s.authService.RefreshToken(ctx, user)
 	if err != nil {
@@ -358,10 +454,15 @@ func (s *SheetsService) CreateSheetsClient(ctx context.Context, user *models.Use
 	return srv, nil
 }
 
-// FetchKPIData fetches KPI data from Google Sheets for a specific month using batch API
-func (s *SheetsService) FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month int) ([]KPIData, error) {
+// FetchKPIData fetches KPI data from the year's spreadsheet for a specific month using batch API
+func (s *SheetsService) FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, error) {
+	ref, ok := SpreadsheetForYear(year)
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrNoSpreadsheetForYear, year)
+	}
+
 	// Get discovered layout
-	layout, err := s.GetLayout(ctx, user)
+	layout, err := s.getLayout(ctx, user, ref)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get layout: %w", err)
 	}
@@ -394,7 +495,7 @@ func (s *SheetsService) FetchKPIData(ctx context.Context, user *models.User, ind
 		return nil, err
 	}
 
-	spreadsheetID := config.AppConfig.SpreadsheetID
+	spreadsheetID := ref.SpreadsheetID
 
 	// Build all ranges for batch request
 	var ranges []string
@@ -411,7 +512,7 @@ func (s *SheetsService) FetchKPIData(ctx context.Context, user *models.User, ind
 		}
 
 		activeIndicators = append(activeIndicators, indicator)
-		rangeStr := fmt.Sprintf("%s!A%d:%s%d", formatSheetName(config.AppConfig.SheetName), row, lastColLetter, row)
+		rangeStr := fmt.Sprintf("%s!A%d:%s%d", formatSheetName(ref.SheetName), row, lastColLetter, row)
 		ranges = append(ranges, rangeStr)
 	}
 
@@ -419,7 +520,7 @@ func (s *SheetsService) FetchKPIData(ctx context.Context, user *models.User, ind
 		return []KPIData{}, nil
 	}
 
-	log.Printf("Batch fetching %d KPIs in single API call for month %d", len(ranges), month)
+	log.Printf("Batch fetching %d KPIs in single API call for month %d, year %d", len(ranges), month, year)
 
 	// Use BatchGet to fetch all ranges in a single API call
 	resp, err := srv.Spreadsheets.Values.BatchGet(spreadsheetID).Ranges(ranges...).Do()
@@ -445,7 +546,7 @@ func (s *SheetsService) FetchKPIData(ctx context.Context, user *models.User, ind
 			break
 		}
 		indicator := activeIndicators[i]
-		kpiData := s.parseKPIRow(valueRange.Values, indicator, targetIdx, percentIdx, perfIdx)
+		kpiData := parseKPIRow(valueRange.Values, indicator, targetIdx, percentIdx, perfIdx)
 		kpiDataList = append(kpiDataList, kpiData)
 	}
 
@@ -454,9 +555,14 @@ func (s *SheetsService) FetchKPIData(ctx context.Context, user *models.User, ind
 }
 
 // FetchSingleKPIData fetches data for a single KPI
-func (s *SheetsService) FetchSingleKPIData(ctx context.Context, user *models.User, indicator models.Indicator, month int) (*KPIData, error) {
+func (s *SheetsService) FetchSingleKPIData(ctx context.Context, user *models.User, indicator models.Indicator, month, year int) (*KPIData, error) {
+	ref, ok := SpreadsheetForYear(year)
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrNoSpreadsheetForYear, year)
+	}
+
 	// Get discovered layout
-	layout, err := s.GetLayout(ctx, user)
+	layout, err := s.getLayout(ctx, user, ref)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get layout: %w", err)
 	}
@@ -489,20 +595,20 @@ func (s *SheetsService) FetchSingleKPIData(ctx context.Context, user *models.Use
 		return nil, err
 	}
 
-	spreadsheetID := config.AppConfig.SpreadsheetID
-	rangeStr := fmt.Sprintf("%s!A%d:%s%d", formatSheetName(config.AppConfig.SheetName), row, lastColLetter, row)
+	spreadsheetID := ref.SpreadsheetID
+	rangeStr := fmt.Sprintf("%s!A%d:%s%d", formatSheetName(ref.SheetName), row, lastColLetter, row)
 
 	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, rangeStr).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
 
-	data := s.parseKPIRow(resp.Values, indicator, targetIdx, percentIdx, perfIdx)
+	data := parseKPIRow(resp.Values, indicator, targetIdx, percentIdx, perfIdx)
 	return &data, nil
 }
 
 // parseKPIRow parses a row of values from the spreadsheet
-func (s *SheetsService) parseKPIRow(values [][]interface{}, indicator models.Indicator, targetIdx, percentIdx, perfIdx int) KPIData {
+func parseKPIRow(values [][]interface{}, indicator models.Indicator, targetIdx, percentIdx, perfIdx int) KPIData {
 	kpiData := KPIData{
 		IndicatorCode: indicator.Code,
 		Department:    indicator.Department,
@@ -584,14 +690,21 @@ func parseFloat(val interface{}) float64 {
 	}
 }
 
-// TestConnection tests if the user has access to the spreadsheet
-func (s *SheetsService) TestConnection(ctx context.Context, user *models.User) error {
-	srv, err := s.CreateSheetsClient(ctx, user)
+// TestConnection tests if the user has access to the year's spreadsheet.
+// The check always uses the user's own token, even when reads go through the service account.
+// Years without a spreadsheet have nothing to check and always pass.
+func (s *SheetsService) TestConnection(ctx context.Context, user *models.User, year int) error {
+	ref, ok := SpreadsheetForYear(year)
+	if !ok {
+		return nil
+	}
+
+	srv, err := s.CreateUserSheetsClient(ctx, user)
 	if err != nil {
 		return err
 	}
 
-	spreadsheetID := config.AppConfig.SpreadsheetID
+	spreadsheetID := ref.SpreadsheetID
 
 	// Try to get spreadsheet metadata
 	_, err = srv.Spreadsheets.Get(spreadsheetID).Do()
@@ -602,6 +715,11 @@ func (s *SheetsService) TestConnection(ctx context.Context, user *models.User) e
 	return nil
 }
 
+// Years returns the years that have a spreadsheet configured
+func (s *SheetsService) Years() []int {
+	return SpreadsheetYears()
+}
+
 // GetTokenForClient creates an oauth2 token from user's stored tokens
 func (s *SheetsService) GetTokenForClient(user *models.User) *oauth2.Token {
 	return &oauth2.Token{
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchMonthFromHeader(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantMonth int
+		wantType  string
+	}{
+		{"January Target", 1, "target"},
+		{"  march lagging ", 3, "lagging"},
+		{"% December Performance", 12, "percent"},
+		{"May", 5, "perf"},
+		{"SEPTEMBER", 9, "perf"},
+		{"Jan Target", 0, ""},
+		{"January Budget", 0, ""},
+		{"% January", 0, ""},
+		{"Leading Indicators", 0, ""},
+		{"", 0, ""},
+	}
+
+	for _, tt := range tests {
+		month, colType := matchMonthFromHeader(tt.header)
+		if month != tt.wantMonth || colType != tt.wantType {
+			t.Errorf("matchMonthFromHeader(%q) = %d, %q, want %d, %q", tt.header, month, colType, tt.wantMonth, tt.wantType)
+		}
+	}
+}
+
+func TestParseHeaderRow(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []interface{}
+		want   map[int][4]int
+	}{
+		{
+			name: "two months in template order",
+			header: []interface{}{"No", "Dept", "Leading Indicators",
+				"January Target", "January Lagging", "% January Performance", "January",
+				"February Target", "February Lagging", "% February Performance", "February"},
+			want: map[int][4]int{
+				1: {3, 4, 5, 6},
+				2: {7, 8, 9, 10},
+			},
+		},
+		{
+			name:   "columns out of order and non-string cells",
+			header: []interface{}{"March", 42, nil, "% March Performance", "March Target"},
+			want: map[int][4]int{
+				3: {4, 0, 3, 0},
+			},
+		},
+		{
+			name:   "no month columns",
+			header: []interface{}{"No", "Dept", "Leading Indicators"},
+			want:   map[int][4]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseHeaderRow(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHeaderRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKPINames(t *testing.T) {
+	tests := []struct {
+		name   string
+		values [][]interface{}
+		want   map[string][]int
+	}{
+		{
+			name: "names are trimmed, lowercased and 1-based",
+			values: [][]interface{}{
+				{"Leading Indicators"},
+				{"  Revenue "},
+				{},
+				{""},
+				{12.5},
+				{"Customer Satisfaction"},
+				{"customer satisfaction"},
+			},
+			want: map[string][]int{
+				"leading indicators":    {1},
+				"revenue":               {2},
+				"customer satisfaction": {6, 7},
+			},
+		},
+		{
+			name:   "empty column",
+			values: nil,
+			want:   map[string][]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKPINames(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKPINames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
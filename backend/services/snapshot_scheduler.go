@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+// snapshotRunTimeout bounds a single automatic snapshot (sheet fetch + save)
+const snapshotRunTimeout = 2 * time.Minute
+
+// SnapshotScheduler takes weekly snapshots automatically on a cron schedule
+// using the designated service user's stored OAuth token
+type SnapshotScheduler struct {
+	dashboardService *DashboardService
+	digestService    *DigestService
+	cron             *cron.Cron
+	entryID          cron.EntryID
+	mu               sync.Mutex
+	runMu            sync.Mutex // Prevents overlapping runs
+}
+
+// NewSnapshotScheduler creates a new SnapshotScheduler instance
+func NewSnapshotScheduler(dashboardService *DashboardService, digestService *DigestService) *SnapshotScheduler {
+	return &SnapshotScheduler{
+		dashboardService: dashboardService,
+		digestService:    digestService,
+		cron:             cron.New(),
+	}
+}
+
+// ValidateSchedule checks a cron expression ("0 8 * * MON", "@weekly", ...)
+func ValidateSchedule(expr string) error {
+	if expr == "" {
+		return nil
+	}
+	if _, err := cron.ParseStandard(expr); err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	return nil
+}
+
+// Start schedules the configured expression and starts the cron loop
+func (s *SnapshotScheduler) Start() {
+	if err := s.Reschedule(config.AppConfig.SnapshotSchedule); err != nil {
+		log.Printf("Warning: snapshot schedule not started: %v", err)
+	}
+	s.cron.Start()
+}
+
+// Stop stops the cron loop and waits for a running snapshot to finish
+func (s *SnapshotScheduler) Stop() {
+	ctx := s.cron.Stop()
+	<-ctx.Done()
+}
+
+// Reschedule replaces the current schedule. An empty expression disables automatic snapshots.
+func (s *SnapshotScheduler) Reschedule(expr string) error {
+	if err := ValidateSchedule(expr); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entryID != 0 {
+		s.cron.Remove(s.entryID)
+		s.entryID = 0
+	}
+
+	if expr == "" {
+		log.Println("Automatic snapshots disabled")
+		return nil
+	}
+
+	id, err := s.cron.AddFunc(expr, func() {
+		if _, err := s.RunNow(models.SnapshotRunScheduled, ""); err != nil {
+			log.Printf("Scheduled snapshot failed: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule snapshots: %w", err)
+	}
+
+	s.entryID = id
+	log.Printf("Automatic snapshots scheduled: '%s' (service user: %s)", expr, config.AppConfig.SnapshotServiceUser)
+	return nil
+}
+
+// NextRun returns the next scheduled run time, or nil when disabled
+func (s *SnapshotScheduler) NextRun() *time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entryID == 0 {
+		return nil
+	}
+	next := s.cron.Entry(s.entryID).Next
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// ErrSnapshotRunInProgress is returned by RunNow while another run is taking a snapshot
+var ErrSnapshotRunInProgress = errors.New("a snapshot run is already in progress")
+
+// RunNow takes a snapshot of the current month and week and records the outcome.
+// The returned run is also stored when the snapshot fails.
+func (s *SnapshotScheduler) RunNow(trigger, triggeredBy string) (*models.SnapshotRun, error) {
+	if !s.runMu.TryLock() {
+		return nil, ErrSnapshotRunInProgress
+	}
+	defer s.runMu.Unlock()
+
+	now := time.Now()
+	period := NewSnapshotPeriod(int(now.Month()), now.Year(), now)
+	run := &models.SnapshotRun{
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		ServiceUser: config.AppConfig.SnapshotServiceUser,
+		Status:      models.SnapshotRunRunning,
+		Month:       period.Month,
+		Year:        period.Year,
+		WeekNumber:  period.WeekNumber,
+		StartedAt:   now,
+	}
+	if err := database.DB.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record snapshot run: %w", err)
+	}
+
+	count, err := s.takeSnapshot(run, period)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.IndicatorCount = count
+	run.Status = models.SnapshotRunSuccess
+	if err != nil {
+		run.Status = models.SnapshotRunFailed
+		run.Error = err.Error()
+	}
+	if saveErr := database.DB.Save(run).Error; saveErr != nil {
+		log.Printf("Warning: Failed to update snapshot run %d: %v", run.ID, saveErr)
+	}
+
+	log.Printf("Snapshot run %d (%s) finished: status=%s month=%d year=%d week=%d indicators=%d",
+		run.ID, trigger, run.Status, run.Month, run.Year, run.WeekNumber, count)
+	return run, err
+}
+
+// takeSnapshot fetches the dashboard as the service user and saves it
+func (s *SnapshotScheduler) takeSnapshot(run *models.SnapshotRun, period SnapshotPeriod) (int, error) {
+	user, err := loadServiceUser(run.ServiceUser)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotRunTimeout)
+	defer cancel()
+
+	dashboardData, err := s.dashboardService.GetDashboardData(ctx, user, run.Month, run.Year)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch dashboard data: %w", err)
+	}
+	if len(dashboardData.Indicators) == 0 {
+		return 0, fmt.Errorf("no indicator data returned for %s %d", getMonthName(run.Month), run.Year)
+	}
+
+	savedBy := "scheduler"
+	if run.TriggeredBy != "" {
+		savedBy = run.TriggeredBy
+	}
+	if _, err := s.dashboardService.SaveSnapshot(dashboardData.Indicators, period, savedBy); err != nil {
+		return 0, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	s.digestService.NotifySnapshot(dashboardData, period.WeekNumber)
+
+	return len(dashboardData.Indicators), nil
+}
+
+// loadServiceUser returns the account whose token background jobs read the sheets with.
+// Without a service user (nil) the sheets are read with the service-account key, if configured.
+func loadServiceUser(email string) (*models.User, error) {
+	if email == "" {
+		if config.AppConfig.GoogleServiceAccountKeyFile == "" {
+			return nil, fmt.Errorf("no snapshot service user or service account configured")
+		}
+		return nil, nil
+	}
+	user := &models.User{}
+	if err := database.DB.Where("email = ?", email).First(user).Error; err != nil {
+		return nil, fmt.Errorf("service user %s not found", email)
+	}
+	return user, nil
+}
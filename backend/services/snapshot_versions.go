@@ -0,0 +1,408 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+// snapshotBatchSize is the number of snapshot rows inserted per statement
+const snapshotBatchSize = 100
+
+var (
+	// ErrSnapshotVersionNotFound is returned when a week has no snapshot with the requested version
+	ErrSnapshotVersionNotFound = errors.New("snapshot version not found")
+	// ErrSnapshotConflict is returned when another save of the same week wrote the same version
+	ErrSnapshotConflict = errors.New("snapshot was saved concurrently, please retry")
+	// ErrIndicatorNotFound is returned when a correction names an unknown indicator code
+	ErrIndicatorNotFound = errors.New("indicator not found")
+	// ErrInvalidSnapshotWeek is returned when a week does not exist in the month
+	ErrInvalidSnapshotWeek = errors.New("week does not exist in this month")
+	// ErrEmptySnapshot is returned when a save has no indicator rows; the current version is kept
+	ErrEmptySnapshot = errors.New("snapshot has no indicator data")
+)
+
+// SnapshotVersion describes one saved version of a week's snapshot
+type SnapshotVersion struct {
+	Version      int       `json:"version"`
+	IsCurrent    bool      `json:"is_current"`
+	SnapshotDate time.Time `json:"snapshot_date"`
+	SavedAt      time.Time `json:"saved_at"`
+	SavedBy      string    `json:"saved_by"`
+	RestoredFrom int       `json:"restored_from,omitempty"`
+	Indicators   int       `json:"indicators"`
+}
+
+// SnapshotValues are the values of one indicator in a snapshot version
+type SnapshotValues struct {
+	Target      float64 `json:"target"`
+	Performance float64 `json:"performance"`
+	Percentage  float64 `json:"percentage"`
+}
+
+// IndicatorDiff compares one indicator between two snapshot versions
+type IndicatorDiff struct {
+	Code       string          `json:"code"`
+	Department string          `json:"department"`
+	Name       string          `json:"name"`
+	Change     string          `json:"change"` // added, removed, changed or unchanged
+	From       *SnapshotValues `json:"from"`
+	To         *SnapshotValues `json:"to"`
+	Delta      float64         `json:"percentage_delta"` // To - From, 0 when either side is missing
+}
+
+// SnapshotDiff compares two versions of a week's snapshot per indicator
+type SnapshotDiff struct {
+	Month       int             `json:"month"`
+	Year        int             `json:"year"`
+	WeekNumber  int             `json:"week_number"`
+	FromVersion int             `json:"from_version"`
+	ToVersion   int             `json:"to_version"`
+	Indicators  []IndicatorDiff `json:"indicators"`
+	Changed     int             `json:"changed"` // Indicators added, removed or changed
+}
+
+// buildSnapshotVersion returns the rows of a new version given the week's current rows
+type buildSnapshotVersion func(current []models.WeeklySnapshot) ([]models.WeeklySnapshot, error)
+
+// saveSnapshotVersion stores the built rows as the next version of a week and makes it current,
+// in one transaction. Saves of the same week are serialised with an advisory lock, so build sees
+// the latest current rows; the unique version index is the backstop. Version numbers also count
+// soft-deleted weeks so they are never reused. Returns the new version and its saved rows, or
+// ErrEmptySnapshot when build returns no rows.
+func saveSnapshotVersion(month, year, week int, build buildSnapshotVersion) (int, []models.WeeklySnapshot, error) {
+	var version int
+	var rows []models.WeeklySnapshot
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockSnapshotWeek(tx, month, year, week); err != nil {
+			return err
+		}
+
+		var current []models.WeeklySnapshot
+		if err := tx.Where("month = ? AND year = ? AND week_number = ? AND is_current = ?", month, year, week, true).
+			Order("id").Find(&current).Error; err != nil {
+			return fmt.Errorf("failed to load current snapshot: %w", err)
+		}
+		built, err := build(current)
+		if err != nil {
+			return err
+		}
+		// Checked before the current version is retired, so an empty save leaves the week as it was
+		if len(built) == 0 {
+			return ErrEmptySnapshot
+		}
+		rows = built
+
+		var latest int
+		if err := tx.Unscoped().Model(&models.WeeklySnapshot{}).
+			Where("month = ? AND year = ? AND week_number = ?", month, year, week).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return fmt.Errorf("failed to find latest snapshot version: %w", err)
+		}
+		version = latest + 1
+
+		if err := tx.Model(&models.WeeklySnapshot{}).
+			Where("month = ? AND year = ? AND week_number = ? AND is_current = ?", month, year, week, true).
+			Update("is_current", false).Error; err != nil {
+			return fmt.Errorf("failed to retire current snapshot version: %w", err)
+		}
+
+		for i := range rows {
+			rows[i].ID = 0
+			rows[i].Month = month
+			rows[i].Year = year
+			rows[i].WeekNumber = week
+			rows[i].Version = version
+			rows[i].IsCurrent = true
+		}
+		return tx.CreateInBatches(rows, snapshotBatchSize).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return 0, nil, ErrSnapshotConflict
+	}
+	if errors.Is(err, ErrEmptySnapshot) {
+		return 0, nil, err
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to save snapshot version: %w", err)
+	}
+	return version, rows, nil
+}
+
+// lockSnapshotWeek takes a transaction-scoped advisory lock on one week's snapshots
+func lockSnapshotWeek(tx *gorm.DB, month, year, week int) error {
+	key := int64(year)*1000 + int64(month)*10 + int64(week)
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", key).Error; err != nil {
+		return fmt.Errorf("failed to lock snapshot week: %w", err)
+	}
+	return nil
+}
+
+// ListSnapshotVersions returns the saved versions of a week, newest first
+func (s *DashboardService) ListSnapshotVersions(month, year, week int) ([]SnapshotVersion, error) {
+	var snapshots []models.WeeklySnapshot
+	if err := database.DB.Where("month = ? AND year = ? AND week_number = ?", month, year, week).
+		Order("version desc, id").
+		Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	versions := []SnapshotVersion{}
+	for _, snap := range snapshots {
+		n := len(versions)
+		if n == 0 || versions[n-1].Version != snap.Version {
+			versions = append(versions, SnapshotVersion{
+				Version:      snap.Version,
+				IsCurrent:    snap.IsCurrent,
+				SnapshotDate: snap.SnapshotDate,
+				SavedAt:      snap.CreatedAt,
+				SavedBy:      snap.SavedBy,
+				RestoredFrom: snap.RestoredFrom,
+			})
+			n++
+		}
+		versions[n-1].Indicators++
+	}
+	return versions, nil
+}
+
+// getSnapshotVersion loads the rows of one version of a week
+func getSnapshotVersion(month, year, week, version int) ([]models.WeeklySnapshot, error) {
+	var snapshots []models.WeeklySnapshot
+	if err := database.DB.Where("month = ? AND year = ? AND week_number = ? AND version = ?", month, year, week, version).
+		Order("id").
+		Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrSnapshotVersionNotFound
+	}
+	return snapshots, nil
+}
+
+// DiffSnapshotVersions compares two versions of a week per indicator, in the order of the "to" version
+func (s *DashboardService) DiffSnapshotVersions(month, year, week, fromVersion, toVersion int) (*SnapshotDiff, error) {
+	from, err := getSnapshotVersion(month, year, week, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getSnapshotVersion(month, year, week, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SnapshotDiff{
+		Month:       month,
+		Year:        year,
+		WeekNumber:  week,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+	}
+	diff.Indicators, diff.Changed = diffSnapshotRows(from, to)
+	return diff, nil
+}
+
+// diffSnapshotRows compares the rows of two versions per indicator, in the order of the "to"
+// rows followed by the indicators only present in "from". Returns the entries and how many
+// of them are not unchanged.
+func diffSnapshotRows(from, to []models.WeeklySnapshot) ([]IndicatorDiff, int) {
+	entries := []IndicatorDiff{}
+
+	fromByCode := make(map[string]models.WeeklySnapshot, len(from))
+	for _, snap := range from {
+		fromByCode[snap.IndicatorID] = snap
+	}
+
+	for _, snap := range to {
+		entry := IndicatorDiff{
+			Code:       snap.IndicatorID,
+			Department: snap.Department,
+			Name:       snap.IndicatorName,
+			To:         snapshotValues(snap),
+			Change:     "added",
+		}
+		if prev, ok := fromByCode[snap.IndicatorID]; ok {
+			entry.From = snapshotValues(prev)
+			entry.Delta = math.Round((snap.Percentage-prev.Percentage)*100) / 100
+			entry.Change = "unchanged"
+			if *entry.From != *entry.To {
+				entry.Change = "changed"
+			}
+			delete(fromByCode, snap.IndicatorID)
+		}
+		entries = append(entries, entry)
+	}
+
+	// Indicators only present in the older version
+	for _, snap := range from {
+		if _, ok := fromByCode[snap.IndicatorID]; !ok {
+			continue
+		}
+		entries = append(entries, IndicatorDiff{
+			Code:       snap.IndicatorID,
+			Department: snap.Department,
+			Name:       snap.IndicatorName,
+			From:       snapshotValues(snap),
+			Change:     "removed",
+		})
+	}
+
+	changed := 0
+	for _, entry := range entries {
+		if entry.Change != "unchanged" {
+			changed++
+		}
+	}
+	return entries, changed
+}
+
+// snapshotValues extracts the values of a snapshot row
+func snapshotValues(snap models.WeeklySnapshot) *SnapshotValues {
+	return &SnapshotValues{
+		Target:      snap.TargetValue,
+		Performance: snap.PerformanceValue,
+		Percentage:  snap.Percentage,
+	}
+}
+
+// RestoreSnapshotVersion makes an older version current again by saving a copy of it as a
+// new version, so the history stays linear. Returns the new version number.
+func (s *DashboardService) RestoreSnapshotVersion(month, year, week, version int, restoredBy string) (int, error) {
+	rows, err := getSnapshotVersion(month, year, week, version)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range rows {
+		resetSnapshotRow(&rows[i], restoredBy)
+		rows[i].RestoredFrom = version
+	}
+
+	var replaced []models.WeeklySnapshot
+	newVersion, saved, err := saveSnapshotVersion(month, year, week, func(current []models.WeeklySnapshot) ([]models.WeeklySnapshot, error) {
+		replaced = current
+		return rows, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Restored snapshot version %d of month %d, week %d, year %d as version %d", version, month, week, year, newVersion)
+	s.snapshotSaved(SnapshotSavedEvent{
+		Month:        month,
+		Year:         year,
+		Week:         week,
+		Version:      newVersion,
+		Source:       "restore",
+		SavedBy:      restoredBy,
+		Indicators:   len(rows),
+		RestoredFrom: version,
+	}, saved, replaced)
+	return newVersion, nil
+}
+
+// resetSnapshotRow prepares a copy of an existing row for insertion into a new version
+func resetSnapshotRow(row *models.WeeklySnapshot, savedBy string) {
+	row.ID = 0
+	row.CreatedAt = time.Time{}
+	row.UpdatedAt = time.Time{}
+	row.SavedBy = savedBy
+	row.RestoredFrom = 0
+}
+
+// MaxSnapshotValue bounds hand-entered targets and performances to what the snapshot value
+// columns (decimal(15,2)) can store
+const MaxSnapshotValue = 1e13
+
+// CorrectSnapshotValue sets an indicator's target and performance in a week's snapshot by hand.
+// The percentage is recomputed like the dashboard does and the row is flagged as manual with
+// the reason. The correction is saved as a new version: the current rows are copied with the
+// indicator's row replaced, or added when the week or indicator had no snapshot yet.
+func (s *DashboardService) CorrectSnapshotValue(month, year, week int, code string, target, performance float64, reason, editedBy string) (*models.WeeklySnapshot, int, error) {
+	var indicator models.Indicator
+	result := database.DB.Where("code = ?", code).Limit(1).Find(&indicator)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, 0, ErrIndicatorNotFound
+	}
+
+	weekDate, ok := DateForWeekOfMonth(month, year, week, config.AppConfig.WeekStartDay)
+	if !ok {
+		return nil, 0, ErrInvalidSnapshotWeek
+	}
+
+	var replaced []models.WeeklySnapshot
+	version, rows, err := saveSnapshotVersion(month, year, week, func(current []models.WeeklySnapshot) ([]models.WeeklySnapshot, error) {
+		replaced = current
+		rows := make([]models.WeeklySnapshot, 0, len(current)+1)
+		found := false
+		for _, row := range current {
+			resetSnapshotRow(&row, editedBy)
+			if row.IndicatorID == code {
+				found = true
+				applyCorrection(&row, target, performance, reason)
+			}
+			rows = append(rows, row)
+		}
+
+		if !found {
+			date := weekDate
+			if len(current) > 0 {
+				date = current[0].SnapshotDate
+			}
+			period := NewSnapshotPeriod(month, year, date)
+			row := models.WeeklySnapshot{
+				IndicatorID:   indicator.Code,
+				Department:    indicator.Department,
+				IndicatorName: indicator.Name,
+				SnapshotDate:  date,
+				ISOYear:       period.ISOYear,
+				ISOWeek:       period.ISOWeek,
+				SavedBy:       editedBy,
+			}
+			applyCorrection(&row, target, performance, reason)
+			rows = append(rows, row)
+		}
+		return rows, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.snapshotSaved(SnapshotSavedEvent{
+		Month:      month,
+		Year:       year,
+		Week:       week,
+		Version:    version,
+		Source:     "correction",
+		SavedBy:    editedBy,
+		Indicators: len(rows),
+	}, rows, replaced)
+
+	for i := range rows {
+		if rows[i].IndicatorID == code {
+			log.Printf("Corrected %s in snapshot of month %d, week %d, year %d (version %d): %s", code, month, week, year, version, reason)
+			return &rows[i], version, nil
+		}
+	}
+	return nil, version, nil
+}
+
+// applyCorrection writes manually entered values to a snapshot row
+func applyCorrection(row *models.WeeklySnapshot, target, performance float64, reason string) {
+	row.TargetValue = target
+	row.PerformanceValue = performance
+	row.Percentage = calculatePercentage(target, performance)
+	row.IsManual = true
+	row.ManualReason = reason
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+)
+
+func TestApplyCorrection(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         float64
+		performance    float64
+		wantPercentage float64
+	}{
+		{"recomputes percentage", 400, 300, 75},
+		{"zero target", 0, 300, 0},
+		{"clamped", 0.5, 1e6, 999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := models.WeeklySnapshot{
+				IndicatorID:      "FIN-01",
+				TargetValue:      100,
+				PerformanceValue: 90,
+				Percentage:       90,
+			}
+			applyCorrection(&row, tt.target, tt.performance, "late invoice")
+
+			if row.TargetValue != tt.target || row.PerformanceValue != tt.performance {
+				t.Errorf("values = %v/%v, want %v/%v", row.TargetValue, row.PerformanceValue, tt.target, tt.performance)
+			}
+			if row.Percentage != tt.wantPercentage {
+				t.Errorf("percentage = %v, want %v", row.Percentage, tt.wantPercentage)
+			}
+			if !row.IsManual || row.ManualReason != "late invoice" {
+				t.Errorf("manual flag = %v %q, want true %q", row.IsManual, row.ManualReason, "late invoice")
+			}
+			if row.IndicatorID != "FIN-01" {
+				t.Errorf("indicator changed to %q", row.IndicatorID)
+			}
+		})
+	}
+}
+
+func TestDiffSnapshotRows(t *testing.T) {
+	row := func(code string, target, performance, percentage float64) models.WeeklySnapshot {
+		return models.WeeklySnapshot{
+			IndicatorID:      code,
+			Department:       "FIN",
+			IndicatorName:    "Indicator " + code,
+			TargetValue:      target,
+			PerformanceValue: performance,
+			Percentage:       percentage,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		from        []models.WeeklySnapshot
+		to          []models.WeeklySnapshot
+		wantChanges []string // Per entry, in order
+		wantDeltas  []float64
+		wantChanged int
+	}{
+		{
+			name:        "identical versions",
+			from:        []models.WeeklySnapshot{row("A", 100, 90, 90), row("B", 50, 50, 100)},
+			to:          []models.WeeklySnapshot{row("A", 100, 90, 90), row("B", 50, 50, 100)},
+			wantChanges: []string{"unchanged", "unchanged"},
+			wantDeltas:  []float64{0, 0},
+			wantChanged: 0,
+		},
+		{
+			name:        "changed value",
+			from:        []models.WeeklySnapshot{row("A", 100, 90, 90)},
+			to:          []models.WeeklySnapshot{row("A", 100, 95.5, 95.5)},
+			wantChanges: []string{"changed"},
+			wantDeltas:  []float64{5.5},
+			wantChanged: 1,
+		},
+		{
+			name:        "target changed with the same percentage",
+			from:        []models.WeeklySnapshot{row("A", 100, 50, 50)},
+			to:          []models.WeeklySnapshot{row("A", 200, 100, 50)},
+			wantChanges: []string{"changed"},
+			wantDeltas:  []float64{0},
+			wantChanged: 1,
+		},
+		{
+			name:        "added and removed, removed listed last",
+			from:        []models.WeeklySnapshot{row("A", 100, 90, 90), row("B", 50, 50, 100)},
+			to:          []models.WeeklySnapshot{row("C", 10, 5, 50), row("A", 100, 90, 90)},
+			wantChanges: []string{"added", "unchanged", "removed"},
+			wantDeltas:  []float64{0, 0, 0},
+			wantChanged: 2,
+		},
+		{
+			name:        "both empty",
+			wantChanges: []string{},
+			wantDeltas:  []float64{},
+			wantChanged: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, changed := diffSnapshotRows(tt.from, tt.to)
+			if entries == nil {
+				t.Fatal("entries is nil, want an empty list for JSON")
+			}
+			if len(entries) != len(tt.wantChanges) {
+				t.Fatalf("got %d entries, want %d", len(entries), len(tt.wantChanges))
+			}
+			for i, entry := range entries {
+				if entry.Change != tt.wantChanges[i] {
+					t.Errorf("entry %d (%s) change = %q, want %q", i, entry.Code, entry.Change, tt.wantChanges[i])
+				}
+				if entry.Delta != tt.wantDeltas[i] {
+					t.Errorf("entry %d (%s) delta = %v, want %v", i, entry.Code, entry.Delta, tt.wantDeltas[i])
+				}
+				if entry.Change == "added" && entry.From != nil {
+					t.Errorf("entry %d: added indicator has from values", i)
+				}
+				if entry.Change == "removed" && entry.To != nil {
+					t.Errorf("entry %d: removed indicator has to values", i)
+				}
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %d, want %d", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestSaveSnapshotVersion(t *testing.T) {
+	openTestDB(t, "weekly_snapshots")
+
+	const month, year, week = 3, 2026, 2
+	date := time.Date(year, month, 10, 12, 0, 0, 0, time.Local)
+	rows := func(percentages ...float64) buildSnapshotVersion {
+		return func(current []models.WeeklySnapshot) ([]models.WeeklySnapshot, error) {
+			built := make([]models.WeeklySnapshot, 0, len(percentages))
+			for i, pct := range percentages {
+				built = append(built, models.WeeklySnapshot{
+					IndicatorID:   fmt.Sprintf("IND-%d", i+1),
+					Department:    "FIN",
+					IndicatorName: "Indicator",
+					Percentage:    pct,
+					SnapshotDate:  date,
+				})
+			}
+			return built, nil
+		}
+	}
+	current := func() []models.WeeklySnapshot {
+		var snaps []models.WeeklySnapshot
+		database.DB.Where("month = ? AND year = ? AND week_number = ? AND is_current = ?", month, year, week, true).
+			Order("id").Find(&snaps)
+		return snaps
+	}
+
+	steps := []struct {
+		name        string
+		build       buildSnapshotVersion
+		wantErr     error
+		wantVersion int // Current version afterwards
+		wantRows    int // Current rows afterwards
+	}{
+		{"first save", rows(80, 90), nil, 1, 2},
+		{"second save replaces the first", rows(85), nil, 2, 1},
+		{"empty save keeps the current version", rows(), ErrEmptySnapshot, 2, 1},
+		{"failed build keeps the current version", func([]models.WeeklySnapshot) ([]models.WeeklySnapshot, error) {
+			return nil, ErrIndicatorNotFound
+		}, ErrIndicatorNotFound, 2, 1},
+		{"save after a rejected one takes the next version", rows(70, 75, 95), nil, 3, 3},
+	}
+
+	for _, step := range steps {
+		version, saved, err := saveSnapshotVersion(month, year, week, step.build)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", step.name, err, step.wantErr)
+		}
+		if err == nil && (version != step.wantVersion || len(saved) != step.wantRows) {
+			t.Errorf("%s: saved version %d with %d rows, want %d with %d", step.name, version, len(saved), step.wantVersion, step.wantRows)
+		}
+
+		snaps := current()
+		if len(snaps) != step.wantRows {
+			t.Fatalf("%s: %d current rows, want %d", step.name, len(snaps), step.wantRows)
+		}
+		for _, snap := range snaps {
+			if snap.Version != step.wantVersion {
+				t.Errorf("%s: current row %s has version %d, want %d", step.name, snap.IndicatorID, snap.Version, step.wantVersion)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"log"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+)
+
+// Thresholds holds the status bands in effect for one dashboard calculation
+type Thresholds struct {
+	Default    models.StatusThreshold
+	Inverse    models.StatusThreshold
+	Overall    models.StatusThreshold
+	Indicators map[string]models.StatusThreshold
+}
+
+// LoadThresholds reads the threshold profiles from the database,
+// falling back to the built-in defaults for any missing global scope
+func LoadThresholds() *Thresholds {
+	t := &Thresholds{
+		Indicators: make(map[string]models.StatusThreshold),
+	}
+	for _, def := range models.GetDefaultThresholds() {
+		t.setGlobal(def)
+	}
+
+	var rows []models.StatusThreshold
+	if err := database.DB.Find(&rows).Error; err != nil {
+		log.Printf("Warning: Failed to load status thresholds, using defaults: %v", err)
+		return t
+	}
+
+	for _, row := range rows {
+		if row.Scope == models.ThresholdScopeIndicator {
+			t.Indicators[row.IndicatorCode] = row
+		} else {
+			t.setGlobal(row)
+		}
+	}
+
+	return t
+}
+
+func (t *Thresholds) setGlobal(row models.StatusThreshold) {
+	switch row.Scope {
+	case models.ThresholdScopeDefault:
+		t.Default = row
+	case models.ThresholdScopeInverse:
+		t.Inverse = row
+	case models.ThresholdScopeOverall:
+		t.Overall = row
+	}
+}
+
+// For returns the bands for an indicator: its own override, else the global normal or inverse profile
+func (t *Thresholds) For(indicatorCode string, isInverse bool) models.StatusThreshold {
+	if band, ok := t.Indicators[indicatorCode]; ok {
+		return band
+	}
+	if isInverse {
+		return t.Inverse
+	}
+	return t.Default
+}
+
+// Status grades an indicator percentage using its bands
+func (t *Thresholds) Status(indicatorCode string, percentage float64, isInverse bool) string {
+	return calculateStatus(percentage, isInverse, t.For(indicatorCode, isInverse))
+}
+
+// OverallStatus grades the overall performance percentage
+func (t *Thresholds) OverallStatus(percentage float64) string {
+	if percentage > t.Overall.Middle {
+		return "green"
+	} else if percentage > t.Overall.Lower {
+		return "yellow"
+	}
+	return "red"
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+)
+
+// UploadSource serves KPI data from the active uploaded workbook
+type UploadSource struct {
+	mu         sync.RWMutex
+	workbookID uint
+	year       int
+	parsed     *ParsedWorkbook
+}
+
+// NewUploadSource creates a new UploadSource instance
+func NewUploadSource() *UploadSource {
+	return &UploadSource{}
+}
+
+// loadYear returns the parsed active workbook if it holds data for the given year
+func (u *UploadSource) loadYear(year int) (*ParsedWorkbook, error) {
+	parsed, err := u.load()
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.RLock()
+	workbookYear := u.year
+	u.mu.RUnlock()
+	if workbookYear != year {
+		return nil, fmt.Errorf("active uploaded workbook holds %d data, not %d", workbookYear, year)
+	}
+	return parsed, nil
+}
+
+// load returns the parsed active workbook, re-parsing only when the active upload changed
+func (u *UploadSource) load() (*ParsedWorkbook, error) {
+	var workbook models.UploadedWorkbook
+	result := database.DB.Select("id").Where("is_active = ?", true).Order("uploaded_at desc").First(&workbook)
+	if result.Error != nil {
+		return nil, fmt.Errorf("no active uploaded workbook")
+	}
+
+	u.mu.RLock()
+	if u.parsed != nil && u.workbookID == workbook.ID {
+		defer u.mu.RUnlock()
+		return u.parsed, nil
+	}
+	u.mu.RUnlock()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := database.DB.First(&workbook, workbook.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load uploaded workbook: %w", err)
+	}
+
+	parsed, err := ParseWorkbook(workbook.Filename, workbook.FileData, workbook.SheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse uploaded workbook %s: %w", workbook.Filename, err)
+	}
+
+	log.Printf("Loaded uploaded workbook %s (id=%d, year=%d)", workbook.Filename, workbook.ID, workbook.Year)
+	u.workbookID = workbook.ID
+	u.year = workbook.Year
+	u.parsed = parsed
+	return parsed, nil
+}
+
+// FetchKPIData reads KPI values for a month from the active uploaded workbook
+func (u *UploadSource) FetchKPIData(ctx context.Context, user *models.User, indicators []models.Indicator, month, year int) ([]KPIData, error) {
+	parsed, err := u.loadYear(year)
+	if err != nil {
+		return nil, err
+	}
+
+	monthCols, ok := parsed.Layout.MonthColumns[month]
+	if !ok {
+		return nil, fmt.Errorf("month %d not found in uploaded workbook", month)
+	}
+
+	targetIdx := monthCols[0]
+	percentIdx := monthCols[2]
+	perfIdx := monthCols[3]
+
+	var kpiDataList []KPIData
+	for _, indicator := range indicators {
+		if !indicator.IsActive {
+			continue
+		}
+
+		row, found := getIndicatorRow(parsed.Layout, indicator)
+		if !found {
+			continue
+		}
+
+		var values [][]interface{}
+		if row >= 1 && row <= len(parsed.Rows) {
+			values = [][]interface{}{parsed.Rows[row-1]}
+		}
+		kpiDataList = append(kpiDataList, parseKPIRow(values, indicator, targetIdx, percentIdx, perfIdx))
+	}
+
+	log.Printf("Read %d KPIs from uploaded workbook for month %d", len(kpiDataList), month)
+	return kpiDataList, nil
+}
+
+// GetLayout returns the layout discovered from the active uploaded workbook
+func (u *UploadSource) GetLayout(ctx context.Context, user *models.User, year int) (*DiscoveredLayout, error) {
+	parsed, err := u.loadYear(year)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Layout, nil
+}
+
+// TestConnection checks that an uploaded workbook is available
+func (u *UploadSource) TestConnection(ctx context.Context, user *models.User, year int) error {
+	_, err := u.load()
+	return err
+}
+
+// Years returns the year of the active uploaded workbook
+func (u *UploadSource) Years() []int {
+	if _, err := u.load(); err != nil {
+		return nil
+	}
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return []int{u.year}
+}
+
+// InvalidateLayout drops the parsed workbook so the next read reloads it
+func (u *UploadSource) InvalidateLayout() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.workbookID = 0
+	u.year = 0
+	u.parsed = nil
+}
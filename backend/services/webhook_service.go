@@ -0,0 +1,396 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"gorm.io/gorm"
+)
+
+// Webhook delivery settings: attempt n+1 is made webhookRetryBase * 2^(n-1) after attempt n
+// (30s, 1m, 2m, 4m, 8m), and a delivery fails for good after webhookMaxAttempts
+const (
+	webhookTimeout      = 10 * time.Second
+	webhookRetryBase    = 30 * time.Second
+	webhookMaxAttempts  = 6
+	webhookPollInterval = 15 * time.Second
+	webhookBatchSize    = 50
+	webhookMaxBody      = 1024 // Bytes of the response body kept in the delivery log
+	WebhookSecretPrefix = "whsec_"
+)
+
+// ErrWebhookNotFound is returned when a subscription or delivery does not exist
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// WebhookService stores dashboard events as deliveries for each matching subscription and
+// sends them in the background, retrying failures with exponential backoff. Pending deliveries
+// live in the database, so retries survive a restart.
+type WebhookService struct {
+	client *http.Client
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex // Serialises delivery passes
+}
+
+// NewWebhookService creates a new WebhookService instance
+func NewWebhookService() *WebhookService {
+	return &WebhookService{
+		client: &http.Client{Timeout: webhookTimeout},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// WebhookPayload is the JSON body of every webhook request
+type WebhookPayload struct {
+	ID        string      `json:"id"` // Event ID, unchanged on redelivery
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// SnapshotSavedEvent is the data of a snapshot.saved event
+type SnapshotSavedEvent struct {
+	Month        int    `json:"month"`
+	Year         int    `json:"year"`
+	Week         int    `json:"week"`
+	Version      int    `json:"version"`
+	Source       string `json:"source"` // "save", "restore" or "correction"
+	SavedBy      string `json:"saved_by"`
+	Indicators   int    `json:"indicators"`
+	RestoredFrom int    `json:"restored_from,omitempty"`
+}
+
+// SnapshotDeletedEvent is the data of a snapshot.deleted event
+type SnapshotDeletedEvent struct {
+	Month       int   `json:"month"`
+	Year        int   `json:"year"`
+	Week        int   `json:"week"`
+	Snapshots   int64 `json:"snapshots"`
+	Screenshots int64 `json:"screenshots"`
+}
+
+// ScreenshotUploadedEvent is the data of a screenshot.uploaded event
+type ScreenshotUploadedEvent struct {
+	ID         uint   `json:"id"`
+	Month      int    `json:"month"`
+	Year       int    `json:"year"`
+	Week       int    `json:"week"`
+	Filename   string `json:"filename"`
+	SizeBytes  int64  `json:"size_bytes"`
+	UploadedBy string `json:"uploaded_by"`
+}
+
+// KPIRedEvent is the data of a kpi.red event: an indicator that is red in a saved snapshot
+// but was not in the snapshot it replaced (or the previous week's)
+type KPIRedEvent struct {
+	Month              int      `json:"month"`
+	Year               int      `json:"year"`
+	Week               int      `json:"week"`
+	Code               string   `json:"code"`
+	Department         string   `json:"department"`
+	Name               string   `json:"name"`
+	Target             float64  `json:"target"`
+	Performance        float64  `json:"performance"`
+	Percentage         float64  `json:"percentage"`
+	PreviousPercentage *float64 `json:"previous_percentage"` // Nil when there was no earlier value
+	PreviousStatus     string   `json:"previous_status,omitempty"`
+}
+
+// Start runs the delivery worker until Stop is called
+func (s *WebhookService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			s.deliverDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+// Stop stops the delivery worker and waits for the current pass to finish
+func (s *WebhookService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// Emit queues an event for every active subscription to it. Failures are logged, never
+// returned, so that emitting cannot fail the action that caused the event.
+func (s *WebhookService) Emit(event string, data interface{}) {
+	var subscriptions []models.WebhookSubscription
+	if err := database.DB.Where("is_active = ?", true).Find(&subscriptions).Error; err != nil {
+		log.Printf("Warning: Failed to load webhook subscriptions for %s: %v", event, err)
+		return
+	}
+
+	var matching []models.WebhookSubscription
+	for _, sub := range subscriptions {
+		if sub.Subscribes(event) {
+			matching = append(matching, sub)
+		}
+	}
+	if len(matching) == 0 {
+		return
+	}
+
+	eventID, err := newTokenID()
+	if err != nil {
+		log.Printf("Warning: Failed to create webhook event %s: %v", event, err)
+		return
+	}
+	payload, err := json.Marshal(WebhookPayload{ID: eventID, Event: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		log.Printf("Warning: Failed to encode webhook event %s: %v", event, err)
+		return
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(matching))
+	for _, sub := range matching {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			Event:          event,
+			EventID:        eventID,
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  &now,
+		})
+	}
+	if err := database.DB.Create(&deliveries).Error; err != nil {
+		log.Printf("Warning: Failed to queue webhook event %s: %v", event, err)
+		return
+	}
+	s.notify()
+}
+
+// Redeliver queues a copy of a delivery (same event ID and payload) as a new delivery
+func (s *WebhookService) Redeliver(deliveryID uint) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	if err := database.DB.First(&original, deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	var sub models.WebhookSubscription
+	if err := database.DB.First(&sub, original.SubscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		Event:          original.Event,
+		EventID:        original.EventID,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   &original.ID,
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	s.notify()
+	return &delivery, nil
+}
+
+// ListDeliveries returns a page of the delivery log, newest first, optionally for one subscription
+func (s *WebhookService) ListDeliveries(subscriptionID uint, status string, page, pageSize int) ([]models.WebhookDelivery, int64, error) {
+	query := database.DB.Model(&models.WebhookDelivery{})
+	if subscriptionID != 0 {
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// NewWebhookSecret returns a random signing secret
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature value for a body sent at a Unix timestamp:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay returns how long to wait after a delivery's nth failed attempt
+func webhookRetryDelay(attempts int) time.Duration {
+	return webhookRetryBase << (attempts - 1)
+}
+
+// notify wakes the delivery worker without blocking
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverDue attempts every pending delivery whose next attempt is due
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ctx.Err() == nil {
+		var due []models.WebhookDelivery
+		err := database.DB.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+			Order("next_attempt_at ASC, id ASC").
+			Limit(webhookBatchSize).
+			Find(&due).Error
+		if err != nil {
+			log.Printf("Warning: Failed to load due webhook deliveries: %v", err)
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+
+		subscriptions := make(map[uint]*models.WebhookSubscription)
+		for i := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			d := &due[i]
+			sub, ok := subscriptions[d.SubscriptionID]
+			if !ok {
+				sub = &models.WebhookSubscription{}
+				if err := database.DB.First(sub, d.SubscriptionID).Error; err != nil {
+					sub = nil
+				}
+				subscriptions[d.SubscriptionID] = sub
+			}
+			s.attempt(ctx, d, sub)
+		}
+
+		if len(due) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a retry on failure
+func (s *WebhookService) attempt(ctx context.Context, d *models.WebhookDelivery, sub *models.WebhookSubscription) {
+	d.Attempts++
+	d.ResponseStatus = 0
+	d.ResponseBody = ""
+	d.Error = ""
+
+	switch {
+	case sub == nil:
+		d.Error = "subscription was deleted"
+		d.Attempts = webhookMaxAttempts
+	case !sub.IsActive:
+		d.Error = "subscription is disabled"
+		d.Attempts = webhookMaxAttempts
+	default:
+		d.ResponseStatus, d.ResponseBody, d.Error = s.post(ctx, d, sub)
+	}
+
+	now := time.Now()
+	switch {
+	case d.Error == "":
+		d.Status = models.WebhookDeliverySuccess
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+	case d.Attempts >= webhookMaxAttempts:
+		d.Status = models.WebhookDeliveryFailed
+		d.NextAttemptAt = nil
+		log.Printf("Webhook delivery %d (%s) failed after %d attempts: %s", d.ID, d.Event, d.Attempts, d.Error)
+	default:
+		next := now.Add(webhookRetryDelay(d.Attempts))
+		d.NextAttemptAt = &next
+	}
+
+	if err := database.DB.Save(d).Error; err != nil {
+		log.Printf("Warning: Failed to update webhook delivery %d: %v", d.ID, err)
+	}
+}
+
+// post sends a delivery's payload to its subscription and returns the response status and
+// (truncated) body, with an error message unless the receiver answered 2xx
+func (s *WebhookService) post(ctx context.Context, d *models.WebhookDelivery, sub *models.WebhookSubscription) (int, string, string) {
+	body := []byte(d.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "weekly-dashboard-webhooks")
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-ID", d.EventID)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", SignWebhookPayload(sub.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(respBody), fmt.Sprintf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, string(respBody), ""
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"weekly-dashboard/models"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+	}{
+		{"event payload", "whsec_test", 1767225600, `{"event":"snapshot.saved"}`},
+		{"empty body", "whsec_test", 1767225600, ""},
+		{"other secret", "whsec_other", 1, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write([]byte(strconv.FormatInt(tt.timestamp, 10) + "." + tt.body))
+			want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+			if got := SignWebhookPayload(tt.secret, tt.timestamp, []byte(tt.body)); got != want {
+				t.Errorf("SignWebhookPayload() = %s, want %s", got, want)
+			}
+		})
+	}
+
+	// The timestamp is signed, so a replay with another timestamp does not verify
+	if SignWebhookPayload("whsec_test", 1, []byte("{}")) == SignWebhookPayload("whsec_test", 2, []byte("{}")) {
+		t.Error("signatures of different timestamps are equal")
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute}
+	for i, delay := range want {
+		if got := webhookRetryDelay(i + 1); got != delay {
+			t.Errorf("webhookRetryDelay(%d) = %s, want %s", i+1, got, delay)
+		}
+	}
+	// Five retries follow the first attempt before a delivery fails for good
+	if webhookMaxAttempts != len(want)+1 {
+		t.Errorf("webhookMaxAttempts = %d, want %d", webhookMaxAttempts, len(want)+1)
+	}
+}
+
+func TestNewWebhookSecret(t *testing.T) {
+	a, err := NewWebhookSecret()
+	if err != nil {
+		t.Fatalf("NewWebhookSecret() error = %v", err)
+	}
+	b, _ := NewWebhookSecret()
+	if !strings.HasPrefix(a, WebhookSecretPrefix) || a == b {
+		t.Errorf("secrets %q and %q, want distinct values starting with %s", a, b, WebhookSecretPrefix)
+	}
+}
+
+func TestWebhookPost(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantError bool
+	}{
+		{"accepted", http.StatusOK, false},
+		{"no content", http.StatusNoContent, false},
+		{"server error", http.StatusInternalServerError, true},
+		{"redirect is not success", http.StatusNotModified, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				got, body = r, string(b)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			d := &models.WebhookDelivery{ID: 7, Event: models.WebhookEventSnapshotSaved, EventID: "evt_1", Payload: `{"version":2}`}
+			sub := &models.WebhookSubscription{URL: server.URL, Secret: "whsec_test", IsActive: true}
+			status, _, errMsg := NewWebhookService().post(context.Background(), d, sub)
+
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if (errMsg != "") != tt.wantError {
+				t.Errorf("error = %q, want error %v", errMsg, tt.wantError)
+			}
+			if body != d.Payload {
+				t.Errorf("body = %q, want %q", body, d.Payload)
+			}
+			if got.Header.Get("X-Webhook-Event") != d.Event || got.Header.Get("X-Webhook-ID") != "evt_1" || got.Header.Get("X-Webhook-Delivery") != "7" {
+				t.Errorf("event headers = %v", got.Header)
+			}
+			timestamp, err := strconv.ParseInt(got.Header.Get("X-Webhook-Timestamp"), 10, 64)
+			if err != nil {
+				t.Fatalf("invalid timestamp header: %v", err)
+			}
+			if want := SignWebhookPayload(sub.Secret, timestamp, []byte(d.Payload)); got.Header.Get("X-Webhook-Signature") != want {
+				t.Errorf("signature = %s, want %s", got.Header.Get("X-Webhook-Signature"), want)
+			}
+		})
+	}
+}
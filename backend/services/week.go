@@ -0,0 +1,97 @@
+package services
+
+import (
+	"time"
+
+	"weekly-dashboard/config"
+)
+
+// MaxWeeksInMonth is the highest week-of-month a date can fall in
+// (a 31-day month starting on the last day of the week spans 6 weeks)
+const MaxWeeksInMonth = 6
+
+// SnapshotPeriod identifies the calendar week a snapshot belongs to
+type SnapshotPeriod struct {
+	Date       time.Time `json:"snapshot_date"`
+	Month      int       `json:"month"`
+	Year       int       `json:"year"`
+	WeekNumber int       `json:"week_number"` // Week of month, 1-6
+	ISOYear    int       `json:"iso_year"`
+	ISOWeek    int       `json:"iso_week"`
+}
+
+// NewSnapshotPeriod derives the week numbers for a snapshot of month/year taken on date.
+// Dates outside the month are clamped to its first or last day, so a snapshot of a
+// closed month taken later still counts as that month's final week.
+func NewSnapshotPeriod(month, year int, date time.Time) SnapshotPeriod {
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, date.Location())
+	last := first.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	if date.Before(first) {
+		date = first
+	} else if date.After(last) {
+		date = time.Date(year, time.Month(month), daysInMonth(month, year), 12, 0, 0, 0, date.Location())
+	}
+
+	isoYear, isoWeek := date.ISOWeek()
+	return SnapshotPeriod{
+		Date:       date,
+		Month:      month,
+		Year:       year,
+		WeekNumber: WeekOfMonth(date, config.AppConfig.WeekStartDay),
+		ISOYear:    isoYear,
+		ISOWeek:    isoWeek,
+	}
+}
+
+// WeekStart returns midnight of the first day of the period's week-of-month,
+// which is never earlier than the 1st of the month
+func (p SnapshotPeriod) WeekStart() time.Time {
+	start := StartOfWeek(p.Date, config.AppConfig.WeekStartDay)
+	if first := time.Date(p.Year, time.Month(p.Month), 1, 0, 0, 0, 0, p.Date.Location()); start.Before(first) {
+		return first
+	}
+	return start
+}
+
+// WeekOfMonth returns the week of the month (1-6) a date falls in.
+// Week 1 runs from the 1st up to the day before the first weekStart day.
+func WeekOfMonth(date time.Time, weekStart time.Weekday) int {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	offset := (int(first.Weekday()) - int(weekStart) + 7) % 7
+	return (date.Day()-1+offset)/7 + 1
+}
+
+// WeeksInMonth returns how many (partial) weeks a month spans
+func WeeksInMonth(month, year int, weekStart time.Weekday) int {
+	last := time.Date(year, time.Month(month), daysInMonth(month, year), 0, 0, 0, 0, time.Local)
+	return WeekOfMonth(last, weekStart)
+}
+
+// DateForWeekOfMonth returns the first day of week-of-month n, clamped to the 1st for week 1.
+// The second return value is false if the month has no such week.
+func DateForWeekOfMonth(month, year, week int, weekStart time.Weekday) (time.Time, bool) {
+	if week < 1 || week > WeeksInMonth(month, year, weekStart) {
+		return time.Time{}, false
+	}
+
+	first := time.Date(year, time.Month(month), 1, 12, 0, 0, 0, time.Local)
+	offset := (int(first.Weekday()) - int(weekStart) + 7) % 7
+	day := (week-1)*7 - offset + 1
+	if day < 1 {
+		day = 1
+	}
+	return first.AddDate(0, 0, day-1), true
+}
+
+// StartOfWeek returns midnight of the first day of the week containing date
+func StartOfWeek(date time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(date.Weekday()) - int(weekStart) + 7) % 7
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return day.AddDate(0, 0, -offset)
+}
+
+// referenceDate returns the date the dashboard for month/year represents:
+// today while the month is running, otherwise its last (or first) day
+func referenceDate(month, year int) time.Time {
+	return NewSnapshotPeriod(month, year, time.Now()).Date
+}
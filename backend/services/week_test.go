@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekOfMonth(t *testing.T) {
+	tests := []struct {
+		date      string
+		weekStart time.Weekday
+		want      int
+	}{
+		// March 2026 starts on a Sunday: week 1 is the 1st alone when weeks start on Monday
+		{"2026-03-01", time.Monday, 1},
+		{"2026-03-02", time.Monday, 2},
+		{"2026-03-08", time.Monday, 2},
+		{"2026-03-09", time.Monday, 3},
+		{"2026-03-31", time.Monday, 6},
+		{"2026-03-01", time.Sunday, 1},
+		{"2026-03-07", time.Sunday, 1},
+		{"2026-03-08", time.Sunday, 2},
+		// June 2026 starts on a Monday
+		{"2026-06-01", time.Monday, 1},
+		{"2026-06-07", time.Monday, 1},
+		{"2026-06-08", time.Monday, 2},
+		{"2026-06-30", time.Monday, 5},
+		// February 2027 starts on a Monday and has exactly four weeks
+		{"2027-02-28", time.Monday, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date+"/"+tt.weekStart.String(), func(t *testing.T) {
+			date, _ := time.Parse("2006-01-02", tt.date)
+			if got := WeekOfMonth(date, tt.weekStart); got != tt.want {
+				t.Errorf("WeekOfMonth(%s, %s) = %d, want %d", tt.date, tt.weekStart, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeeksInMonth(t *testing.T) {
+	tests := []struct {
+		month, year int
+		weekStart   time.Weekday
+		want        int
+	}{
+		{3, 2026, time.Monday, 6},
+		{3, 2026, time.Sunday, 5},
+		{6, 2026, time.Monday, 5},
+		{2, 2027, time.Monday, 4},
+		{2, 2024, time.Monday, 5}, // Leap year, starts on a Thursday
+	}
+
+	for _, tt := range tests {
+		if got := WeeksInMonth(tt.month, tt.year, tt.weekStart); got != tt.want {
+			t.Errorf("WeeksInMonth(%d, %d, %s) = %d, want %d", tt.month, tt.year, tt.weekStart, got, tt.want)
+		}
+	}
+}
+
+func TestDateForWeekOfMonth(t *testing.T) {
+	tests := []struct {
+		month, year, week int
+		want              string // Empty when the week does not exist
+	}{
+		{3, 2026, 1, "2026-03-01"},
+		{3, 2026, 2, "2026-03-02"},
+		{3, 2026, 6, "2026-03-30"},
+		{3, 2026, 7, ""},
+		{3, 2026, 0, ""},
+		{6, 2026, 1, "2026-06-01"},
+		{6, 2026, 5, "2026-06-29"},
+		{6, 2026, 6, ""},
+	}
+
+	for _, tt := range tests {
+		date, ok := DateForWeekOfMonth(tt.month, tt.year, tt.week, time.Monday)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("DateForWeekOfMonth(%d, %d, %d) = %s, want no such week", tt.month, tt.year, tt.week, date.Format("2006-01-02"))
+			}
+			continue
+		}
+		if !ok || date.Format("2006-01-02") != tt.want {
+			t.Errorf("DateForWeekOfMonth(%d, %d, %d) = %s %v, want %s", tt.month, tt.year, tt.week, date.Format("2006-01-02"), ok, tt.want)
+			continue
+		}
+		// The returned date must fall in the week it was asked for
+		if got := WeekOfMonth(date, time.Monday); got != tt.week {
+			t.Errorf("DateForWeekOfMonth(%d, %d, %d) falls in week %d", tt.month, tt.year, tt.week, got)
+		}
+	}
+}
+
+func TestNewSnapshotPeriod(t *testing.T) {
+	at := func(date string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+		return d.Add(12 * time.Hour)
+	}
+
+	tests := []struct {
+		name        string
+		month, year int
+		date        time.Time
+		wantDate    string
+		wantWeek    int
+		wantISOYear int
+		wantISOWeek int
+	}{
+		{"inside the month", 3, 2026, at("2026-03-18"), "2026-03-18", 4, 2026, 12},
+		{"later date clamps to the last day", 3, 2026, at("2026-04-06"), "2026-03-31", 6, 2026, 14},
+		{"earlier date clamps to the first day", 3, 2026, at("2026-02-20"), "2026-03-01", 1, 2026, 9},
+		{"ISO year differs from the calendar year", 1, 2027, at("2027-01-01"), "2027-01-01", 1, 2026, 53},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewSnapshotPeriod(tt.month, tt.year, tt.date)
+			if got := p.Date.Format("2006-01-02"); got != tt.wantDate {
+				t.Errorf("date = %s, want %s", got, tt.wantDate)
+			}
+			if p.Month != tt.month || p.Year != tt.year {
+				t.Errorf("period = %d/%d, want %d/%d", p.Month, p.Year, tt.month, tt.year)
+			}
+			if p.WeekNumber != tt.wantWeek {
+				t.Errorf("week = %d, want %d", p.WeekNumber, tt.wantWeek)
+			}
+			if p.ISOYear != tt.wantISOYear || p.ISOWeek != tt.wantISOWeek {
+				t.Errorf("ISO week = %d-W%02d, want %d-W%02d", p.ISOYear, p.ISOWeek, tt.wantISOYear, tt.wantISOWeek)
+			}
+		})
+	}
+}
+
+func TestSnapshotPeriodWeekStart(t *testing.T) {
+	tests := []struct {
+		month, year int
+		date        string
+		want        string
+	}{
+		{3, 2026, "2026-03-18", "2026-03-16"},
+		{3, 2026, "2026-03-01", "2026-03-01"}, // The week began in February
+		{6, 2026, "2026-06-07", "2026-06-01"},
+	}
+
+	for _, tt := range tests {
+		date, _ := time.ParseInLocation("2006-01-02", tt.date, time.Local)
+		p := NewSnapshotPeriod(tt.month, tt.year, date.Add(12*time.Hour))
+		if got := p.WeekStart().Format("2006-01-02"); got != tt.want {
+			t.Errorf("WeekStart of %s = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWeeklyReportNotFound is returned when a week has no generated report
+var ErrWeeklyReportNotFound = errors.New("weekly report not found")
+
+// ErrNoSnapshotForWeek is returned when a report is requested for a week without a saved snapshot
+var ErrNoSnapshotForWeek = errors.New("no snapshot saved for this week")
+
+// GenerateWeeklyReport renders the week's current snapshot as a PDF and stores it as the week's
+// report, replacing an earlier one. The month's saved snapshots provide the weekly trend.
+func (s *DashboardService) GenerateWeeklyReport(month, year, week int, generatedBy string) (*models.WeeklyReport, error) {
+	data, err := s.GetSnapshotDashboard(month, year, week)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if data == nil {
+		return nil, ErrNoSnapshotForWeek
+	}
+	return s.storeWeeklyReport(data, week, generatedBy)
+}
+
+// storeWeeklyReport renders a week's snapshot dashboard as a PDF and stores it as the week's report
+func (s *DashboardService) storeWeeklyReport(data *DashboardResponse, week int, generatedBy string) (*models.WeeklyReport, error) {
+	month, year := data.Period.Month, data.Period.Year
+
+	trend, err := s.GetSnapshotsByMonth(month, year)
+	if err != nil {
+		log.Printf("Warning: Failed to load weekly trend for report: %v", err)
+	}
+
+	pdf, err := RenderWeeklyReport(data, week, trend)
+	if err != nil {
+		return nil, err
+	}
+
+	version := 0
+	if summary, err := s.GetSnapshotSummary(month, year, week); err == nil && summary != nil {
+		version = summary.Version
+	}
+
+	report := models.WeeklyReport{
+		Month:           month,
+		Year:            year,
+		Week:            week,
+		Filename:        fmt.Sprintf("%s_%d_Week_%d.pdf", time.Month(month).String(), year, week),
+		PDFData:         pdf,
+		SizeBytes:       int64(len(pdf)),
+		SnapshotVersion: version,
+		GeneratedBy:     generatedBy,
+		GeneratedAt:     time.Now(),
+	}
+
+	// One report per week (database.WeeklyReportWeekIndex): replace it in place
+	err = database.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "month"}, {Name: "year"}, {Name: "week"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"filename", "pdf_data", "size_bytes", "snapshot_version", "generated_by", "generated_at", "updated_at",
+		}),
+	}).Create(&report).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save weekly report: %w", err)
+	}
+
+	log.Printf("Generated weekly report %s (%d bytes) for %s", report.Filename, report.SizeBytes, generatedBy)
+	return &report, nil
+}
+
+// ListWeeklyReports returns the reports of a month without their PDF data
+func (s *DashboardService) ListWeeklyReports(month, year int) ([]models.WeeklyReport, error) {
+	var reports []models.WeeklyReport
+	err := database.DB.Omit("pdf_data").
+		Where("month = ? AND year = ?", month, year).
+		Order("week ASC").
+		Find(&reports).Error
+	return reports, err
+}
+
+// GetWeeklyReport returns a week's report including its PDF data
+func (s *DashboardService) GetWeeklyReport(month, year, week int) (*models.WeeklyReport, error) {
+	var report models.WeeklyReport
+	err := database.DB.Where("month = ? AND year = ? AND week = ?", month, year, week).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWeeklyReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Report layout (A4 portrait, millimetres)
+const (
+	reportMargin     = 12.0
+	reportPageWidth  = 210.0
+	reportContentW   = reportPageWidth - 2*reportMargin
+	reportRowHeight  = 6.5
+	reportPageBottom = 297.0 - 15.0
+)
+
+// reportStatusColors mirror the dashboard's status colours
+var reportStatusColors = map[string][3]int{
+	"supergreen": {27, 94, 32},
+	"green":      {76, 175, 80},
+	"yellow":     {255, 193, 7},
+	"red":        {244, 67, 54},
+}
+
+var reportStatusLabels = map[string]string{
+	"supergreen": "Excellent",
+	"green":      "On target",
+	"yellow":     "At risk",
+	"red":        "Off target",
+}
+
+var reportScheduleLabels = map[string]string{
+	"ahead":       "Ahead",
+	"on_schedule": "On schedule",
+	"behind":      "Behind",
+}
+
+// RenderWeeklyReport draws the dashboard as a PDF: the overall gauge numbers, the weekly trend
+// and one table per department with status colours and week-over-week arrows.
+// trend may be nil when the month's snapshots could not be loaded.
+func RenderWeeklyReport(data *DashboardResponse, week int, trend *MonthlySnapshotsResponse) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(reportMargin, reportMargin, reportMargin)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	generated := time.Now()
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(reportContentW/2, 5, "Generated "+generated.Format("2006-01-02 15:04"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(reportContentW/2, 5, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// Title
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(30, 41, 59)
+	pdf.CellFormat(reportContentW, 10, tr("Weekly KPI Report"), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetTextColor(100, 116, 139)
+	pdf.CellFormat(reportContentW, 6, tr(fmt.Sprintf("%s %d - Week %d", data.Period.MonthName, data.Period.Year, week)), "", 1, "L", false, 0, "")
+	if !data.DataSource.FetchedAt.IsZero() {
+		pdf.CellFormat(reportContentW, 5, "KPI values as of "+data.DataSource.FetchedAt.Format("2006-01-02 15:04"), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	drawReportSummary(pdf, tr, data)
+	drawReportTrend(pdf, tr, data, week, trend)
+
+	// One table per department, in dashboard order
+	departments := []string{}
+	byDepartment := make(map[string][]IndicatorResponse)
+	for _, ind := range data.Indicators {
+		if _, ok := byDepartment[ind.Department]; !ok {
+			departments = append(departments, ind.Department)
+		}
+		byDepartment[ind.Department] = append(byDepartment[ind.Department], ind)
+	}
+	for _, dept := range departments {
+		drawReportDepartment(pdf, tr, dept, byDepartment[dept])
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawReportSummary draws the overall gauge bar and the status and schedule counts
+func drawReportSummary(pdf *gofpdf.Fpdf, tr func(string) string, data *DashboardResponse) {
+	overall := data.OverallPerformance
+	x, y := pdf.GetX(), pdf.GetY()
+
+	// Overall percentage
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetTextColor(30, 41, 59)
+	pdf.CellFormat(reportContentW, 6, "Overall performance", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 24)
+	setReportStatusText(pdf, overall.Status)
+	pdf.CellFormat(45, 12, fmt.Sprintf("%.1f%%", overall.Percentage), "", 0, "L", false, 0, "")
+
+	// Gauge bar, full at 100%
+	barX, barY, barW, barH := x+48, y+9, reportContentW-48, 6.0
+	pdf.SetFillColor(226, 232, 240)
+	pdf.Rect(barX, barY, barW, barH, "F")
+	fill := overall.Percentage / 100
+	if fill > 1 {
+		fill = 1
+	}
+	if fill > 0 {
+		setReportStatusFill(pdf, overall.Status)
+		pdf.Rect(barX, barY, barW*fill, barH, "F")
+	}
+	pdf.SetY(y + 20)
+
+	// Status and schedule counts
+	cards := []struct {
+		label  string
+		value  int
+		status string
+	}{
+		{"Green", overall.GreenCount, "green"},
+		{"Yellow", overall.YellowCount, "yellow"},
+		{"Red", overall.RedCount, "red"},
+		{"Ahead", data.ScheduleSummary.AheadCount, ""},
+		{"On schedule", data.ScheduleSummary.OnScheduleCount, ""},
+		{"Behind", data.ScheduleSummary.BehindCount, ""},
+	}
+	cardW := reportContentW / float64(len(cards))
+	cardY := pdf.GetY()
+	for i, card := range cards {
+		cx := reportMargin + float64(i)*cardW
+		pdf.SetDrawColor(226, 232, 240)
+		pdf.Rect(cx+1, cardY, cardW-2, 16, "D")
+		if card.status != "" {
+			setReportStatusFill(pdf, card.status)
+			pdf.Rect(cx+1, cardY, 2, 16, "F")
+		}
+		pdf.SetXY(cx+4, cardY+2)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(100, 116, 139)
+		pdf.CellFormat(cardW-6, 4, tr(card.label), "", 2, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.SetTextColor(30, 41, 59)
+		pdf.CellFormat(cardW-6, 8, fmt.Sprintf("%d", card.value), "", 0, "L", false, 0, "")
+	}
+	pdf.SetXY(reportMargin, cardY+22)
+}
+
+// drawReportTrend draws the week-over-week change and the month's average percentage per saved week
+func drawReportTrend(pdf *gofpdf.Fpdf, tr func(string) string, data *DashboardResponse, week int, trend *MonthlySnapshotsResponse) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetTextColor(30, 41, 59)
+	pdf.CellFormat(reportContentW, 7, "Weekly trend", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	y := pdf.GetY()
+	drawReportArrow(pdf, reportMargin+2, y+3, data.WeeklyTrend.Direction)
+	pdf.SetX(reportMargin + 7)
+	text := fmt.Sprintf("%+.1f pp average change", data.WeeklyTrend.Change)
+	if cw := data.WeeklyTrend.ComparedWith; cw != nil {
+		text += fmt.Sprintf(" vs %s %d week %d", getMonthName(cw.Month), cw.Year, cw.WeekNumber)
+	} else {
+		text += " (no earlier snapshot)"
+	}
+	if data.WeeklyTrend.GreenCountChange != 0 {
+		text += fmt.Sprintf(", %+d green", data.WeeklyTrend.GreenCountChange)
+	}
+	pdf.SetTextColor(30, 41, 59)
+	pdf.CellFormat(reportContentW-7, 6, tr(text), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	if trend == nil || len(trend.AvailableWeeks) == 0 {
+		pdf.Ln(3)
+		return
+	}
+
+	// Average snapshot percentage per week of the month
+	colW := reportContentW / float64(MaxWeeksInMonth)
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(241, 245, 249)
+	for w := 1; w <= MaxWeeksInMonth; w++ {
+		pdf.CellFormat(colW, reportRowHeight, fmt.Sprintf("Week %d", w), "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 9)
+	for w := 1; w <= MaxWeeksInMonth; w++ {
+		value := "-"
+		if avg, ok := averageSnapshotWeek(trend, w); ok {
+			value = fmt.Sprintf("%.1f%%", avg)
+		}
+		if w == week {
+			pdf.SetFont("Helvetica", "B", 9)
+		}
+		pdf.CellFormat(colW, reportRowHeight, value, "1", 0, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	pdf.Ln(-1)
+	pdf.Ln(5)
+}
+
+// drawReportDepartment draws a department's indicators as a table
+func drawReportDepartment(pdf *gofpdf.Fpdf, tr func(string) string, department string, indicators []IndicatorResponse) {
+	headers := []string{"Indicator", "Target", "Performance", "%", "Status", "WoW", "Variance", "Schedule"}
+	widths := []float64{56, 20, 22, 15, 20, 18, 18, 17}
+
+	// Keep the heading with at least a few rows
+	if pdf.GetY()+7+reportRowHeight*3 > reportPageBottom {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.SetTextColor(30, 41, 59)
+	pdf.CellFormat(reportContentW, 7, tr(department), "", 1, "L", false, 0, "")
+
+	drawHeader := func() {
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetFillColor(241, 245, 249)
+		pdf.SetTextColor(30, 41, 59)
+		for i, h := range headers {
+			align := "R"
+			if i == 0 {
+				align = "L"
+			}
+			pdf.CellFormat(widths[i], reportRowHeight, h, "B", 0, align, true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	drawHeader()
+
+	pdf.SetFont("Helvetica", "", 8)
+	for _, ind := range indicators {
+		if pdf.GetY()+reportRowHeight > reportPageBottom {
+			pdf.AddPage()
+			drawHeader()
+			pdf.SetFont("Helvetica", "", 8)
+		}
+
+		name := ind.Name
+		for len(name) > 3 && pdf.GetStringWidth(tr(name)) > widths[0]-2 {
+			name = string([]rune(name)[:len([]rune(name))-4]) + "..."
+		}
+
+		y := pdf.GetY()
+		pdf.SetTextColor(30, 41, 59)
+		pdf.CellFormat(widths[0], reportRowHeight, tr(name), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], reportRowHeight, formatReportNumber(ind.Target), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], reportRowHeight, formatReportNumber(ind.Performance), "B", 0, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 8)
+		setReportStatusText(pdf, ind.Status)
+		pdf.CellFormat(widths[3], reportRowHeight, fmt.Sprintf("%.1f%%", ind.Percentage), "B", 0, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+
+		// Status pill
+		x := pdf.GetX()
+		setReportStatusFill(pdf, ind.Status)
+		pdf.RoundedRect(x+2, y+1.2, widths[4]-3, reportRowHeight-2.4, 1.5, "1234", "F")
+		pdf.SetFont("Helvetica", "B", 7)
+		pdf.SetTextColor(255, 255, 255)
+		if ind.Status == "yellow" {
+			pdf.SetTextColor(30, 41, 59)
+		}
+		pdf.CellFormat(widths[4], reportRowHeight, reportStatusLabels[ind.Status], "B", 0, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+
+		// Week-over-week arrow and change
+		x = pdf.GetX()
+		drawReportArrow(pdf, x+3, y+reportRowHeight/2, ind.WowDirection)
+		pdf.SetTextColor(30, 41, 59)
+		pdf.CellFormat(widths[5], reportRowHeight, fmt.Sprintf("%+.1f", ind.WowChange), "B", 0, "R", false, 0, "")
+
+		pdf.CellFormat(widths[6], reportRowHeight, fmt.Sprintf("%+.1f%%", ind.Variance), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[7], reportRowHeight, reportScheduleLabels[ind.ScheduleStatus], "B", 0, "R", false, 0, "")
+		pdf.Ln(-1)
+	}
+	pdf.Ln(5)
+}
+
+// drawReportArrow draws a small up (green) or down (red) triangle centred at x, y, or a grey dash
+func drawReportArrow(pdf *gofpdf.Fpdf, x, y float64, direction string) {
+	switch direction {
+	case "up":
+		pdf.SetFillColor(76, 175, 80)
+		pdf.Polygon([]gofpdf.PointType{{X: x - 1.5, Y: y + 1.2}, {X: x + 1.5, Y: y + 1.2}, {X: x, Y: y - 1.3}}, "F")
+	case "down":
+		pdf.SetFillColor(244, 67, 54)
+		pdf.Polygon([]gofpdf.PointType{{X: x - 1.5, Y: y - 1.2}, {X: x + 1.5, Y: y - 1.2}, {X: x, Y: y + 1.3}}, "F")
+	default:
+		pdf.SetFillColor(148, 163, 184)
+		pdf.Rect(x-1.5, y-0.4, 3, 0.8, "F")
+	}
+}
+
+// setReportStatusFill sets the fill colour of a status, grey when unknown
+func setReportStatusFill(pdf *gofpdf.Fpdf, status string) {
+	if c, ok := reportStatusColors[status]; ok {
+		pdf.SetFillColor(c[0], c[1], c[2])
+		return
+	}
+	pdf.SetFillColor(148, 163, 184)
+}
+
+// setReportStatusText sets the text colour of a status; yellow is darkened to stay readable
+func setReportStatusText(pdf *gofpdf.Fpdf, status string) {
+	switch c, ok := reportStatusColors[status]; {
+	case status == "yellow":
+		pdf.SetTextColor(180, 130, 0)
+	case ok:
+		pdf.SetTextColor(c[0], c[1], c[2])
+	default:
+		pdf.SetTextColor(30, 41, 59)
+	}
+}
+
+// formatReportNumber formats a value with thousands separators and up to two decimals
+func formatReportNumber(value float64) string {
+	s := fmt.Sprintf("%.2f", value)
+	intPart, frac := s[:len(s)-3], s[len(s)-2:]
+	sign := ""
+	if intPart[0] == '-' {
+		sign, intPart = "-", intPart[1:]
+	}
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + "," + intPart[i:]
+	}
+	if frac == "00" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + frac
+}
+
+// averageSnapshotWeek returns the average percentage of the indicators saved for a week
+func averageSnapshotWeek(trend *MonthlySnapshotsResponse, week int) (float64, bool) {
+	var total float64
+	var count int
+	for _, ind := range trend.Indicators {
+		for _, w := range ind.Weeks {
+			if w.Week == week {
+				total += w.Percentage
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
+}
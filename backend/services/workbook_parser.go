@@ -0,0 +1,149 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"weekly-dashboard/config"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ParsedWorkbook holds the rows and discovered layout of an uploaded workbook
+type ParsedWorkbook struct {
+	Format    string
+	SheetName string
+	Rows      [][]interface{}
+	Layout    *DiscoveredLayout
+}
+
+// WorkbookFormat returns "xlsx" or "csv" based on the file extension, or "" if unsupported
+func WorkbookFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		return "xlsx"
+	case ".csv":
+		return "csv"
+	}
+	return ""
+}
+
+// ParseWorkbook reads an XLSX or CSV export of the dashboard sheet and discovers its layout
+// using the same header and column C conventions as the live spreadsheet.
+// For XLSX files, preferredSheet is tried first, then the configured sheet name, then the first sheet.
+func ParseWorkbook(filename string, data []byte, preferredSheet string) (*ParsedWorkbook, error) {
+	format := WorkbookFormat(filename)
+
+	var rows [][]string
+	var sheetName string
+	var err error
+
+	switch format {
+	case "xlsx":
+		rows, sheetName, err = readXLSXRows(data, preferredSheet)
+	case "csv":
+		rows, err = readCSVRows(data)
+	default:
+		return nil, fmt.Errorf("unsupported file type '%s', expected .xlsx or .csv", filepath.Ext(filename))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("workbook is empty")
+	}
+
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, cell := range row {
+			values[i][j] = cell
+		}
+	}
+
+	monthCols := parseHeaderRow(values[0])
+	if len(monthCols) == 0 {
+		return nil, fmt.Errorf("no month columns found in header row (expected e.g. 'January Target', '%% January Performance', 'January')")
+	}
+
+	// Column C (index 2) holds the KPI names, same as the live spreadsheet
+	kpiColumn := make([][]interface{}, len(values))
+	for i, row := range values {
+		if len(row) > 2 {
+			kpiColumn[i] = []interface{}{row[2]}
+		}
+	}
+
+	return &ParsedWorkbook{
+		Format:    format,
+		SheetName: sheetName,
+		Rows:      values,
+		Layout:    newDiscoveredLayout(monthCols, parseKPINames(kpiColumn)),
+	}, nil
+}
+
+// readXLSXRows returns the formatted cell values of the selected worksheet
+func readXLSXRows(data []byte, preferredSheet string) ([][]string, string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open xlsx file: %w", err)
+	}
+	defer f.Close()
+
+	sheetList := f.GetSheetList()
+	if len(sheetList) == 0 {
+		return nil, "", fmt.Errorf("xlsx file has no worksheets")
+	}
+
+	sheetName := sheetList[0]
+	for _, candidate := range []string{preferredSheet, config.AppConfig.SheetName, "DashboardTemplate"} {
+		if match := findSheet(sheetList, candidate); match != "" {
+			sheetName = match
+			break
+		}
+	}
+
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read worksheet '%s': %w", sheetName, err)
+	}
+
+	return rows, sheetName, nil
+}
+
+// findSheet matches a sheet name ignoring case and spaces ("Dashboard Template" == "DashboardTemplate")
+func findSheet(sheetList []string, name string) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	}
+
+	if name == "" {
+		return ""
+	}
+	for _, sheet := range sheetList {
+		if normalize(sheet) == normalize(name) {
+			return sheet
+		}
+	}
+	return ""
+}
+
+// readCSVRows parses a CSV file, allowing ragged rows and a leading UTF-8 BOM
+func readCSVRows(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv file: %w", err)
+	}
+
+	return rows, nil
+}
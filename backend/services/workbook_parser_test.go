@@ -0,0 +1,47 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWorkbookCSV(t *testing.T) {
+	csv := "No,Dept,Leading Indicators,January Target,January Lagging,% January Performance,January\n" +
+		"1,FIN,Revenue,100,80,90%,90\n" +
+		"2,OPS,Turn Over,5,4,60%,3\n"
+
+	parsed, err := ParseWorkbook("export.csv", []byte(csv), "")
+	if err != nil {
+		t.Fatalf("ParseWorkbook() error = %v", err)
+	}
+	if parsed.Format != "csv" {
+		t.Errorf("format = %q, want csv", parsed.Format)
+	}
+	if want := map[int][4]int{1: {3, 4, 5, 6}}; !reflect.DeepEqual(parsed.Layout.MonthColumns, want) {
+		t.Errorf("month columns = %v, want %v", parsed.Layout.MonthColumns, want)
+	}
+	want := map[string][]int{"leading indicators": {1}, "revenue": {2}, "turn over": {3}}
+	if !reflect.DeepEqual(parsed.Layout.KPIRows, want) {
+		t.Errorf("KPI rows = %v, want %v", parsed.Layout.KPIRows, want)
+	}
+}
+
+func TestParseWorkbookErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+	}{
+		{"unsupported extension", "export.xls", "January\n"},
+		{"empty file", "export.csv", ""},
+		{"no month columns", "export.csv", "No,Dept,Leading Indicators\n1,FIN,Revenue\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseWorkbook(tt.filename, []byte(tt.data), ""); err == nil {
+				t.Error("ParseWorkbook() error = nil, want an error")
+			}
+		})
+	}
+}
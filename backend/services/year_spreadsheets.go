@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"weekly-dashboard/config"
+	"weekly-dashboard/database"
+	"weekly-dashboard/models"
+)
+
+// ErrNoSpreadsheetForYear is returned when no spreadsheet is mapped to the requested year
+var ErrNoSpreadsheetForYear = errors.New("no spreadsheet configured for year")
+
+// SpreadsheetRef identifies the sheet holding one year's KPI data
+type SpreadsheetRef struct {
+	Year          int    `json:"year"`
+	SpreadsheetID string `json:"spreadsheet_id"`
+	SheetName     string `json:"sheet_name"`
+	IsDefault     bool   `json:"is_default"` // Comes from the spreadsheet settings, not the year mapping
+}
+
+// key identifies the sheet in layout caches
+func (r SpreadsheetRef) key() string {
+	return r.SpreadsheetID + "!" + r.SheetName
+}
+
+// SpreadsheetForYear returns the sheet for a year. A year_spreadsheets row wins;
+// otherwise the configured spreadsheet is used for the configured spreadsheet year.
+func SpreadsheetForYear(year int) (SpreadsheetRef, bool) {
+	var mapping models.YearSpreadsheet
+	result := database.DB.Where("year = ?", year).Limit(1).Find(&mapping)
+	if result.Error == nil && result.RowsAffected > 0 {
+		return SpreadsheetRef{
+			Year:          mapping.Year,
+			SpreadsheetID: mapping.SpreadsheetID,
+			SheetName:     mapping.SheetName,
+		}, true
+	}
+
+	if year == config.AppConfig.SpreadsheetYear && config.AppConfig.SpreadsheetID != "" {
+		return SpreadsheetRef{
+			Year:          year,
+			SpreadsheetID: config.AppConfig.SpreadsheetID,
+			SheetName:     config.AppConfig.SheetName,
+			IsDefault:     true,
+		}, true
+	}
+
+	return SpreadsheetRef{}, false
+}
+
+// ListSpreadsheets returns the sheet of every configured year, oldest first
+func ListSpreadsheets() []SpreadsheetRef {
+	var mappings []models.YearSpreadsheet
+	database.DB.Order("year").Find(&mappings)
+
+	refs := make([]SpreadsheetRef, 0, len(mappings)+1)
+	hasDefaultYear := false
+	for _, mapping := range mappings {
+		if mapping.Year == config.AppConfig.SpreadsheetYear {
+			hasDefaultYear = true
+		}
+		refs = append(refs, SpreadsheetRef{
+			Year:          mapping.Year,
+			SpreadsheetID: mapping.SpreadsheetID,
+			SheetName:     mapping.SheetName,
+		})
+	}
+
+	if !hasDefaultYear {
+		if ref, ok := SpreadsheetForYear(config.AppConfig.SpreadsheetYear); ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Year < refs[j].Year })
+	return refs
+}
+
+// SpreadsheetYears returns the years that have a spreadsheet, oldest first
+func SpreadsheetYears() []int {
+	var years []int
+	for _, ref := range ListSpreadsheets() {
+		years = append(years, ref.Year)
+	}
+	return years
+}
@@ -49,13 +49,15 @@ function Header() {
             >
               📊 Monthly Charts
             </Link>
-            <button 
-              className={`nav-link settings-btn`}
-              onClick={() => setShowSettings(true)}
-              title="Spreadsheet Settings"
-            >
-              ⚙️ Settings
-            </button>
+            {user?.role === 'admin' && (
+              <button 
+                className={`nav-link settings-btn`}
+                onClick={() => setShowSettings(true)}
+                title="Spreadsheet Settings"
+              >
+                ⚙️ Settings
+              </button>
+            )}
           </nav>
           
           <div className="header-right">
@@ -1,18 +1,22 @@
 import { useState, useEffect, useCallback } from 'react'
-import { useNavigate, useLocation } from 'react-router-dom'
-import api from '../services/api'
-import { AuthContext, TOKEN_KEY } from './authContextValue'
+import { useNavigate } from 'react-router-dom'
+import api, { authApi, getAccessToken, setAccessToken } from '../services/api'
+import { AuthContext } from './authContextValue'
 
 export function AuthProvider({ children }) {
   const [user, setUser] = useState(null)
   const [loading, setLoading] = useState(true)
   const [isAuthenticated, setIsAuthenticated] = useState(false)
   const navigate = useNavigate()
-  const location = useLocation()
 
   // Logout function - defined first so it can be used in fetchUser
   const logout = useCallback(() => {
-    localStorage.removeItem(TOKEN_KEY)
+    // Revoke the session server-side; the local state is cleared regardless
+    const token = getAccessToken()
+    if (token) {
+      authApi.logout(token).catch(() => {})
+    }
+    setAccessToken(null)
     setUser(null)
     setIsAuthenticated(false)
     navigate('/login')
@@ -34,31 +38,13 @@ export function AuthProvider({ children }) {
     }
   }, [logout])
 
-  // Initialize auth state from token
+  // Restore the session from the refresh cookie; this also completes the OAuth callback
   useEffect(() => {
-    const token = localStorage.getItem(TOKEN_KEY)
-    if (token) {
-      fetchUser()
-    } else {
-      setLoading(false)
-    }
+    authApi.refresh()
+      .then(() => fetchUser())
+      .catch(() => setLoading(false))
   }, [fetchUser])
 
-  // Handle OAuth callback token
-  useEffect(() => {
-    const params = new URLSearchParams(location.search)
-    const token = params.get('token')
-    
-    if (token && location.pathname === '/auth/callback') {
-      localStorage.setItem(TOKEN_KEY, token)
-      // Clear the token from URL
-      window.history.replaceState({}, '', '/dashboard')
-      fetchUser().then(() => {
-        navigate('/dashboard', { replace: true })
-      })
-    }
-  }, [location, navigate, fetchUser])
-
   const login = () => {
     // Redirect to Google OAuth
     window.location.href = `${import.meta.env.VITE_API_URL || 'http://localhost:8080/api/v1'}/auth/google`
@@ -66,7 +52,7 @@ export function AuthProvider({ children }) {
 
   // logout is now defined above fetchUser
 
-  const getToken = () => localStorage.getItem(TOKEN_KEY)
+  const getToken = () => getAccessToken()
 
   return (
     <AuthContext.Provider value={{ 
@@ -1,4 +1,3 @@
 import { createContext } from 'react'
 
 export const AuthContext = createContext(null)
-export const TOKEN_KEY = 'auth_token'
@@ -1,21 +1,17 @@
 import { useEffect } from 'react'
-import { useNavigate, useSearchParams } from 'react-router-dom'
+import { useNavigate } from 'react-router-dom'
 import LoadingSpinner from '../components/LoadingSpinner'
+import { useAuth } from '../context/useAuth'
 
 function AuthCallback() {
   const navigate = useNavigate()
-  const [searchParams] = useSearchParams()
+  const { loading, isAuthenticated } = useAuth()
 
+  // The backend set the refresh cookie; AuthProvider exchanges it for an access token
   useEffect(() => {
-    const token = searchParams.get('token')
-    
-    if (token) {
-      localStorage.setItem('auth_token', token)
-      navigate('/dashboard', { replace: true })
-    } else {
-      navigate('/login', { replace: true })
-    }
-  }, [searchParams, navigate])
+    if (loading) return
+    navigate(isAuthenticated ? '/dashboard' : '/login', { replace: true })
+  }, [loading, isAuthenticated, navigate])
 
   return (
     <div className="flex-center" style={{ minHeight: '100vh' }}>
@@ -10,11 +10,14 @@ import LoadingSpinner from '../components/LoadingSpinner'
 import WeekSelectorModal from '../components/WeekSelectorModal'
 import ScreenshotGallery from '../components/ScreenshotGallery'
 import { useDashboard } from '../hooks/useDashboard'
+import { useAuth } from '../context/useAuth'
 import { useToast } from '../context/ToastContext'
 import { screenshotApi, dashboardApi } from '../services/api'
 import './DashboardPage.css'
 
 function DashboardPage() {
+  const { user } = useAuth()
+  const canEdit = user?.role === 'editor' || user?.role === 'admin'
   const [searchParams] = useSearchParams()
   const initialMonth = parseInt(searchParams.get('month')) || new Date().getMonth() + 1
   const initialYear = parseInt(searchParams.get('year')) || new Date().getFullYear()
@@ -201,7 +204,7 @@ function DashboardPage() {
             month={month} 
             year={year} 
             refreshTrigger={refreshGallery}
-            onSnapshot={() => setShowWeekModal(true)}
+            onSnapshot={canEdit ? () => setShowWeekModal(true) : undefined}
             savingScreenshot={savingScreenshot}
           />
         </div>
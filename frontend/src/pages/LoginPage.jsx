@@ -1,9 +1,19 @@
 import { useAuth } from '../context/useAuth'
-import { Navigate } from 'react-router-dom'
+import { Navigate, useSearchParams } from 'react-router-dom'
 import './LoginPage.css'
 
+// Reasons the backend gives when it rejects a Google sign-in
+const LOGIN_ERRORS = {
+  email_denied: 'This account has been blocked from signing in.',
+  domain_not_allowed: 'Your email domain is not allowed. Please sign in with your company account.',
+  email_not_allowed: 'Your account is not on the list of allowed users. Please contact your administrator.',
+  email_unverified: 'Your Google account email is not verified.',
+}
+
 function LoginPage() {
   const { isAuthenticated, login, loading } = useAuth()
+  const [searchParams] = useSearchParams()
+  const errorReason = searchParams.get('error')
 
   if (loading) {
     return null
@@ -23,6 +33,12 @@ function LoginPage() {
         </div>
         
         <div className="login-content">
+          {errorReason && (
+            <p className="login-error">
+              {LOGIN_ERRORS[errorReason] || 'Sign-in failed. Please try again.'}
+            </p>
+          )}
+
           <button className="btn btn-google" onClick={login}>
             <svg width="24" height="24" viewBox="0 0 24 24" fill="none">
               <path d="M22.56 12.25c0-.78-.07-1.53-.2-2.25H12v4.26h5.92c-.26 1.37-1.04 2.53-2.21 3.31v2.77h3.57c2.08-1.92 3.28-4.74 3.28-8.09z" fill="#4285F4"/>
@@ -11,11 +11,40 @@ const api = axios.create({
   withCredentials: true,
 })
 
+// The access token is kept in memory only; the refresh token lives in an HttpOnly cookie
+let accessToken = null
+
+export const setAccessToken = (token) => {
+  accessToken = token
+}
+
+export const getAccessToken = () => accessToken
+
+// Shared in-flight refresh, so concurrent 401s rotate the refresh cookie only once
+let refreshPromise = null
+
+export const refreshAccessToken = () => {
+  if (!refreshPromise) {
+    refreshPromise = api.post('/auth/refresh')
+      .then((response) => {
+        setAccessToken(response.data.data.access_token)
+        return response.data.data.access_token
+      })
+      .finally(() => {
+        refreshPromise = null
+      })
+  }
+  return refreshPromise
+}
+
+const isAuthRequest = (config) =>
+  ['/auth/refresh', '/auth/logout'].some((path) => config?.url?.startsWith(path))
+
 // Request interceptor to add auth token
 api.interceptors.request.use(
   (config) => {
-    const token = localStorage.getItem('auth_token')
-    if (token) {
+    const token = accessToken
+    if (token && !config.headers.Authorization) {
       config.headers.Authorization = `Bearer ${token}`
     }
     return config
@@ -28,12 +57,25 @@ api.interceptors.request.use(
 // Response interceptor for error handling
 api.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
     if (error.response) {
-      // Handle 401 Unauthorized
-      if (error.response.status === 401) {
-        localStorage.removeItem('auth_token')
-        window.location.href = '/login'
+      // Handle 401 Unauthorized: refresh the access token once and retry
+      if (error.response.status === 401 && !isAuthRequest(error.config)) {
+        const original = error.config
+        if (!original._retried) {
+          original._retried = true
+          try {
+            const token = await refreshAccessToken()
+            original.headers.Authorization = `Bearer ${token}`
+            return api(original)
+          } catch {
+            // Fall through to the login redirect
+          }
+        }
+        setAccessToken(null)
+        if (window.location.pathname !== '/login') {
+          window.location.href = '/login'
+        }
       }
       
       // Handle 403 Forbidden (no spreadsheet access)
@@ -88,8 +130,15 @@ export const authApi = {
   getCurrentUser: () => 
     api.get('/auth/me'),
   
-  logout: () => 
-    api.post('/auth/logout'),
+  refresh: () =>
+    refreshAccessToken(),
+
+  // The token is passed explicitly because it is cleared from memory right away
+  logout: (token) => 
+    api.post('/auth/logout', null, { headers: { Authorization: `Bearer ${token}` } }),
+
+  logoutAll: () =>
+    api.post('/auth/logout-all'),
 }
 
 export const settingsApi = {